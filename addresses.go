@@ -0,0 +1,129 @@
+package ipv4opt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/netip"
+)
+
+// AddressFromNetIP returns ip as an Address. ip must be an IPv4 address.
+func AddressFromNetIP(ip net.IP) (Address, error) {
+	ip4 := ip.To4()
+	if ip4 == nil {
+		return 0, fmt.Errorf("%w: %v", ErrBadAddress, ip)
+	}
+	return Address(binary.BigEndian.Uint32(ip4)), nil
+}
+
+// AddressFromAddr returns a as an Address. a must be an IPv4 or
+// IPv4-mapped IPv6 address.
+func AddressFromAddr(a netip.Addr) (Address, error) {
+	a = a.Unmap()
+	if !a.Is4() {
+		return 0, fmt.Errorf("%w: %v", ErrBadAddress, a)
+	}
+	b := a.As4()
+	return Address(binary.BigEndian.Uint32(b[:])), nil
+}
+
+// NetIP returns the address as a net.IP.
+func (addr Address) NetIP() net.IP {
+	return net.IPv4(byte(addr>>24), byte(addr>>16), byte(addr>>8), byte(addr))
+}
+
+// Addr returns the address as a netip.Addr.
+func (addr Address) Addr() netip.Addr {
+	return netip.AddrFrom4([4]byte{byte(addr >> 24), byte(addr >> 16), byte(addr >> 8), byte(addr)})
+}
+
+// RouteFromNetIP returns ip as a Route. ip must be an IPv4 address.
+func RouteFromNetIP(ip net.IP) (Route, error) {
+	addr, err := AddressFromNetIP(ip)
+	return Route(addr), err
+}
+
+// RouteFromAddr returns a as a Route. a must be an IPv4 or IPv4-mapped IPv6
+// address.
+func RouteFromAddr(a netip.Addr) (Route, error) {
+	addr, err := AddressFromAddr(a)
+	return Route(addr), err
+}
+
+// NetIP returns the route as a net.IP.
+func (r Route) NetIP() net.IP {
+	return Address(r).NetIP()
+}
+
+// Addr returns the route as a netip.Addr.
+func (r Route) Addr() netip.Addr {
+	return Address(r).Addr()
+}
+
+// asAddressList returns the address list of options that carry one.
+func asAddressList(opt IPOption) (AddressList, bool) {
+	switch o := opt.(type) {
+	case SDB:
+		return o.AddressList, true
+	case UMP:
+		return o.AddressList, true
+	}
+	rr, ok := asRR(opt)
+	return rr.AddressList, ok
+}
+
+// asRR returns the route data of record route style options.
+func asRR(opt IPOption) (RR, bool) {
+	switch o := opt.(type) {
+	case RR:
+		return o, true
+	case LSRR:
+		return o.RR, true
+	case SSRR:
+		return o.RR, true
+	}
+	return RR{}, false
+}
+
+// WalkAddresses calls fn for every address carried in the record route,
+// source route and timestamp options in the list, along with the type of the
+// option it was found in. Walking stops when fn returns false.
+func (o Options) WalkAddresses(fn func(src OptionType, a Address) bool) {
+	for _, opt := range o {
+		if rr, ok := asRR(opt); ok {
+			for _, r := range rr.Routes {
+				if !fn(rr.Type(), Address(r)) {
+					return
+				}
+			}
+			continue
+		}
+		ts, ok := opt.(TS)
+		if !ok || ts.Flags == TSOnly {
+			continue
+		}
+		for _, s := range ts.Stamps {
+			if !fn(ts.Type(), s.Addr) {
+				return
+			}
+		}
+	}
+}
+
+// ContainsAny returns the first address carried in the options that appears
+// in denylist.
+func (o Options) ContainsAny(denylist []Address) (Address, bool) {
+	deny := make(map[Address]struct{}, len(denylist))
+	for _, a := range denylist {
+		deny[a] = struct{}{}
+	}
+	var found Address
+	var ok bool
+	o.WalkAddresses(func(_ OptionType, a Address) bool {
+		if _, ok = deny[a]; ok {
+			found = a
+		}
+		return !ok
+	})
+	return found, ok
+}
@@ -0,0 +1,88 @@
+package ipv4opt_test
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func TestWalkAddresses(t *testing.T) {
+	ops, err := ipv4opt.Parse(rrTest)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	var count int
+	ops.WalkAddresses(func(src ipv4opt.OptionType, a ipv4opt.Address) bool {
+		if src != ipv4opt.RecordRoute {
+			t.Fatalf("Wrong source type, Expected(%v), Got(%v)", ipv4opt.RecordRoute, src)
+		}
+		if a>>24 == 10 {
+			return false
+		}
+		count++
+		return true
+	})
+	if count != 7 {
+		t.Fatalf("Wrong address count, Expected(%v), Got(%v)", 7, count)
+	}
+}
+
+func TestContainsAny(t *testing.T) {
+	ops, err := ipv4opt.Parse(rrTest)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	denylist := []ipv4opt.Address{
+		167772161,
+		1114453158,
+	}
+	a, ok := ops.ContainsAny(denylist)
+	if !ok || a != 1114453158 {
+		t.Fatalf("Wrong match, Expected(%v), Got(%v, %v)", ipv4opt.Address(1114453158), a, ok)
+	}
+	if _, ok := ops.ContainsAny(denylist[:1]); ok {
+		t.Fatalf("Unexpected match for %v", denylist[:1])
+	}
+}
+
+func TestAddressConversions(t *testing.T) {
+	tests := []struct {
+		ip   net.IP
+		addr netip.Addr
+		err  error
+	}{
+		{net.IPv4(192, 168, 0, 1), netip.MustParseAddr("192.168.0.1"), nil},
+		{net.ParseIP("::ffff:10.0.0.1"), netip.MustParseAddr("::ffff:10.0.0.1"), nil},
+		{net.IPv6loopback, netip.IPv6Loopback(), ipv4opt.ErrBadAddress},
+		{nil, netip.Addr{}, ipv4opt.ErrBadAddress},
+	}
+	for _, test := range tests {
+		a, err := ipv4opt.AddressFromNetIP(test.ip)
+		if !errors.Is(err, test.err) {
+			t.Fatalf("Expected(%v), Got(%v)", test.err, err)
+		}
+		b, err := ipv4opt.AddressFromAddr(test.addr)
+		if !errors.Is(err, test.err) {
+			t.Fatalf("Expected(%v), Got(%v)", test.err, err)
+		}
+		if err != nil {
+			continue
+		}
+		if a != b {
+			t.Fatalf("Expected(%v), Got(%v)", a, b)
+		}
+		if !a.NetIP().Equal(test.ip) {
+			t.Fatalf("Expected(%v), Got(%v)", test.ip, a.NetIP())
+		}
+		if a.Addr() != test.addr.Unmap() {
+			t.Fatalf("Expected(%v), Got(%v)", test.addr.Unmap(), a.Addr())
+		}
+		r, err := ipv4opt.RouteFromAddr(test.addr)
+		if err != nil || r.Addr() != a.Addr() || !r.NetIP().Equal(test.ip) {
+			t.Fatalf("Expected(%v), Got(%v, %v)", a, r, err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package ipv4opt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"slices"
+)
+
+// AnonPolicy maps an address to the address it is replaced with when options
+// are anonymized.
+type AnonPolicy func(Address) Address
+
+// AnonZero returns a policy replacing every address with 0.0.0.0.
+func AnonZero() AnonPolicy {
+	return func(Address) Address {
+		return 0
+	}
+}
+
+// AnonPrefixPreserving returns a policy replacing addresses with a keyed
+// hash that preserves prefixes, in the manner of Crypto-PAn: two addresses
+// sharing their first n bits are replaced with addresses sharing their first
+// n bits. Each bit of the result is the bit of the address flipped by a bit
+// of the HMAC-SHA256 of the bits before it, so the mapping is one to one and
+// is the same for the same key.
+func AnonPrefixPreserving(key []byte) AnonPolicy {
+	key = slices.Clone(key)
+	return func(a Address) Address {
+		mac := hmac.New(sha256.New, key)
+		var out Address
+		var prefix [5]byte
+		var sum []byte
+		for i := 0; i < 32; i++ {
+			prefix[4] = byte(i)
+			mac.Reset()
+			mac.Write(prefix[:])
+			sum = mac.Sum(sum[:0])
+			bit := Address(1) << (31 - i)
+			out |= (a & bit) ^ (Address(sum[0]>>7) << (31 - i))
+			if a&bit != 0 {
+				prefix[i/8] |= 1 << (7 - i%8)
+			}
+		}
+		return out
+	}
+}
+
+// Anonymize returns a copy of the options with the addresses recorded in
+// route, timestamp and traceroute options replaced as p selects. Unused
+// entries, which hold 0.0.0.0, are left as they are so that the options keep
+// their meaning. The data of the returned options matches their fields.
+func (o Options) Anonymize(p AnonPolicy) Options {
+	anon := make(Options, len(o))
+	for i, opt := range o {
+		switch v := opt.(type) {
+		case RR:
+			v.AddressList = v.anonymize(p)
+			opt = v
+		case LSRR:
+			v.AddressList = v.anonymize(p)
+			opt = v
+		case SSRR:
+			v.AddressList = v.anonymize(p)
+			opt = v
+		case SDB:
+			v.AddressList = v.anonymize(p)
+			opt = v
+		case UMP:
+			v.AddressList = v.anonymize(p)
+			opt = v
+		case TS:
+			v.Stamps = slices.Clone(v.Stamps)
+			for j := range v.Stamps {
+				v.Stamps[j].Addr = anonymizeAddress(p, v.Stamps[j].Addr)
+			}
+			v.Recompute()
+			opt = v
+		case Traceroute:
+			v.Originator = anonymizeAddress(p, v.Originator)
+			v.option.data, _ = v.Marshal()
+			opt = v
+		}
+		anon[i] = opt
+	}
+	return anon
+}
+
+// anonymize returns a copy of the list with its routes replaced as p selects.
+func (l AddressList) anonymize(p AnonPolicy) AddressList {
+	l.Routes = slices.Clone(l.Routes)
+	for i, r := range l.Routes {
+		l.Routes[i] = Route(anonymizeAddress(p, Address(r)))
+	}
+	l.Recompute()
+	return l
+}
+
+// anonymizeAddress returns a replaced as p selects, leaving 0.0.0.0 as is.
+func anonymizeAddress(p AnonPolicy, a Address) Address {
+	if a == 0 {
+		return 0
+	}
+	return p(a)
+}
@@ -0,0 +1,68 @@
+package ipv4opt_test
+
+import (
+	"bytes"
+	"math/bits"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func TestAnonymize(t *testing.T) {
+	data := []byte{
+		131, 11, 8, 192, 0, 2, 1, 0, 0, 0, 0,
+		68, 12, 13, 3, 198, 51, 100, 9, 0, 0, 0, 5,
+		82, 12, 0, 1, 0, 2, 0, 3, 192, 0, 2, 7,
+	}
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	for _, p := range []ipv4opt.AnonPolicy{ipv4opt.AnonZero(), ipv4opt.AnonPrefixPreserving([]byte("key"))} {
+		anon := ops.Anonymize(p)
+		lsrr := anon[0].(ipv4opt.LSRR)
+		if lsrr.Routes[1] != 0 || lsrr.Pointer != 8 {
+			t.Fatalf("Wrong anonymized route, Got(%v)", lsrr)
+		}
+		if lsrr.Routes[0] == ops[0].(ipv4opt.LSRR).Routes[0] {
+			t.Fatalf("Route not anonymized, Got(%v)", lsrr)
+		}
+		if ts := anon[1].(ipv4opt.TS); ts.Stamps[0].Addr == ops[1].(ipv4opt.TS).Stamps[0].Addr || ts.Stamps[0].Time != 5 {
+			t.Fatalf("Timestamp not anonymized, Got(%v)", ts)
+		}
+		if tr := anon[2].(ipv4opt.Traceroute); tr.Originator == ops[2].(ipv4opt.Traceroute).Originator {
+			t.Fatalf("Traceroute not anonymized, Got(%v)", tr)
+		}
+		for i, opt := range anon {
+			b, err := ipv4opt.Options{opt}.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(b, opt.Data()) {
+				t.Fatalf("Option %d, Data does not match fields, Expected(%v), Got(%v)", i, b, opt.Data())
+			}
+		}
+		if ops[0].(ipv4opt.LSRR).Routes[0].String() != "192.0.2.1" {
+			t.Fatalf("Original options modified, Got(%v)", ops[0])
+		}
+	}
+}
+
+func TestAnonPrefixPreserving(t *testing.T) {
+	p := ipv4opt.AnonPrefixPreserving([]byte("key"))
+	addrs := []ipv4opt.Address{0xc0000201, 0xc0000202, 0xc0000281, 0xc6336401, 0x0a000001}
+	for _, a := range addrs {
+		if p(a) != p(a) {
+			t.Fatalf("Mapping of %v is not stable", a)
+		}
+		for _, b := range addrs {
+			expected := bits.LeadingZeros32(uint32(a ^ b))
+			if got := bits.LeadingZeros32(uint32(p(a) ^ p(b))); got != expected {
+				t.Fatalf("Shared prefix of %v and %v, Expected(%v), Got(%v)", a, b, expected, got)
+			}
+		}
+	}
+	if q := ipv4opt.AnonPrefixPreserving([]byte("other")); q(addrs[0]) == p(addrs[0]) {
+		t.Fatalf("Mapping does not depend on the key")
+	}
+}
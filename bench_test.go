@@ -0,0 +1,89 @@
+package ipv4opt_test
+
+import (
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+// benchInputs are representative option buffers: the router alert carried by
+// IGMP and MLD-style signalling, and full record route and timestamp options
+// as sent by ping -R and ping -T.
+var benchInputs = []struct {
+	name string
+	data []byte
+}{
+	{"RouterAlert", []byte{148, 4, 0, 0}},
+	{"RecordRoute", rrTest},
+	{"TSOnly", tsTest},
+	{"TSAndAddr", tsTest2},
+	{"Mixed", append(append([]byte{148, 4, 0, 0, 1}, sidTest...), 1, 1, 1)},
+}
+
+func BenchmarkParse(b *testing.B) {
+	for _, in := range benchInputs {
+		b.Run(in.name, func(b *testing.B) {
+			b.SetBytes(int64(len(in.data)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := ipv4opt.Parse(in.data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParseInto(b *testing.B) {
+	for _, in := range benchInputs {
+		b.Run(in.name, func(b *testing.B) {
+			b.SetBytes(int64(len(in.data)))
+			b.ReportAllocs()
+			var opts ipv4opt.Options
+			for i := 0; i < b.N; i++ {
+				var err error
+				opts, err = ipv4opt.ParseInto(in.data, opts.Reset())
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	for _, in := range benchInputs {
+		opts, err := ipv4opt.Parse(in.data)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(in.name, func(b *testing.B) {
+			b.SetBytes(int64(len(in.data)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := opts.Marshal(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkAppendTo(b *testing.B) {
+	for _, in := range benchInputs {
+		opts, err := ipv4opt.Parse(in.data)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(in.name, func(b *testing.B) {
+			b.SetBytes(int64(len(in.data)))
+			b.ReportAllocs()
+			buf := make([]byte, 0, ipv4opt.MaxOptionsLen)
+			for i := 0; i < b.N; i++ {
+				if _, err := opts.AppendTo(buf[:0]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,388 @@
+package ipv4opt
+
+import "encoding"
+
+var (
+	_ encoding.BinaryMarshaler   = Options(nil)
+	_ encoding.BinaryUnmarshaler = (*Options)(nil)
+
+	// Every option type can be unmarshaled on its own.
+	_ encoding.BinaryUnmarshaler = (*EOOList)(nil)
+	_ encoding.BinaryUnmarshaler = (*NoOp)(nil)
+	_ encoding.BinaryUnmarshaler = (*Sec)(nil)
+	_ encoding.BinaryUnmarshaler = (*BasicSec)(nil)
+	_ encoding.BinaryUnmarshaler = (*RR)(nil)
+	_ encoding.BinaryUnmarshaler = (*LSRR)(nil)
+	_ encoding.BinaryUnmarshaler = (*SSRR)(nil)
+	_ encoding.BinaryUnmarshaler = (*SDB)(nil)
+	_ encoding.BinaryUnmarshaler = (*UMP)(nil)
+	_ encoding.BinaryUnmarshaler = (*UInt16Option)(nil)
+	_ encoding.BinaryUnmarshaler = (*StreamID)(nil)
+	_ encoding.BinaryUnmarshaler = (*TS)(nil)
+	_ encoding.BinaryUnmarshaler = (*RouterAlert)(nil)
+	_ encoding.BinaryUnmarshaler = (*CIPSO)(nil)
+	_ encoding.BinaryUnmarshaler = (*ESec)(nil)
+	_ encoding.BinaryUnmarshaler = (*MTUProbe)(nil)
+	_ encoding.BinaryUnmarshaler = (*MTUReply)(nil)
+	_ encoding.BinaryUnmarshaler = (*Traceroute)(nil)
+	_ encoding.BinaryUnmarshaler = (*QuickStart)(nil)
+	_ encoding.BinaryUnmarshaler = (*Experimental)(nil)
+	_ encoding.BinaryUnmarshaler = (*RawOption)(nil)
+)
+
+// MarshalBinary returns the wire format of the options without any padding
+// after them, so that UnmarshalBinary returns the same list.
+func (o Options) MarshalBinary() ([]byte, error) {
+	return o.appendUnpadded(nil)
+}
+
+// UnmarshalBinary sets the options to those parsed from data.
+func (o *Options) UnmarshalBinary(data []byte) error {
+	opts, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	*o = opts
+	return nil
+}
+
+// unmarshalBinary parses data, which must hold exactly one option of one of
+// types, with parse. Any type is accepted when types is empty. Options that
+// could not fit in a header are rejected.
+func unmarshalBinary(data []byte, parse parseFunc, types ...OptionType) (IPOption, error) {
+	if err := checkBinary(data, types...); err != nil {
+		return nil, err
+	}
+	opt, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if opt.Length() != len(data) {
+		return nil, ErrInvalidOptionLength
+	}
+	return opt, nil
+}
+
+// checkBinary checks that data could hold one option of one of types, see
+// unmarshalBinary.
+func checkBinary(data []byte, types ...OptionType) error {
+	if len(data) == 0 {
+		return ErrTruncatedOption
+	}
+	if len(data) > MaxOptionsLen {
+		return ErrOptionDataTooLarge
+	}
+	if len(types) > 0 {
+		var ok bool
+		for _, t := range types {
+			ok = ok || OptionType(data[0]) == t
+		}
+		if !ok {
+			return ErrOptionType
+		}
+	}
+	return nil
+}
+
+// checkWholeOption checks that the option at the start of data, whose length
+// field must be at least min, is exactly as long as data.
+func checkWholeOption(data []byte, min int) error {
+	length, err := optionLength(data, min)
+	if err != nil {
+		return err
+	}
+	if length != len(data) {
+		return ErrInvalidOptionLength
+	}
+	return nil
+}
+
+// unmarshalAddressList is unmarshalBinary for the address list options. It
+// decodes data into l, reusing the storage kept by Reset.
+func unmarshalAddressList(l *AddressList, data []byte, types ...OptionType) error {
+	if err := checkBinary(data, types...); err != nil {
+		return err
+	}
+	if err := checkWholeOption(data, 3); err != nil {
+		return err
+	}
+	return l.decode(data)
+}
+
+// MarshalBinary returns the wire format of the option.
+func (opt EOOList) MarshalBinary() ([]byte, error) {
+	return opt.Marshal()
+}
+
+// UnmarshalBinary sets the option to the one parsed from data.
+func (opt *EOOList) UnmarshalBinary(data []byte) error {
+	o, err := unmarshalBinary(data, parseEOOList, EndOfOptionList)
+	if err != nil {
+		return err
+	}
+	*opt = o.(EOOList)
+	return nil
+}
+
+// MarshalBinary returns the wire format of the option.
+func (opt NoOp) MarshalBinary() ([]byte, error) {
+	return opt.Marshal()
+}
+
+// UnmarshalBinary sets the option to the one parsed from data.
+func (opt *NoOp) UnmarshalBinary(data []byte) error {
+	o, err := unmarshalBinary(data, parseNOOP, NoOperation)
+	if err != nil {
+		return err
+	}
+	*opt = o.(NoOp)
+	return nil
+}
+
+// MarshalBinary returns the wire format of the option.
+func (s Sec) MarshalBinary() ([]byte, error) {
+	return s.Marshal()
+}
+
+// UnmarshalBinary sets the option to the one parsed from data.
+func (s *Sec) UnmarshalBinary(data []byte) error {
+	o, err := unmarshalBinary(data, parseSecurity, Security)
+	if err != nil {
+		return err
+	}
+	*s = o.(Sec)
+	return nil
+}
+
+// MarshalBinary returns the wire format of the option.
+func (bs BasicSec) MarshalBinary() ([]byte, error) {
+	return bs.Marshal()
+}
+
+// UnmarshalBinary sets the option to the one parsed from data.
+func (bs *BasicSec) UnmarshalBinary(data []byte) error {
+	o, err := unmarshalBinary(data, parseBasicSecurity, Security)
+	if err != nil {
+		return err
+	}
+	*bs = o.(BasicSec)
+	return nil
+}
+
+// MarshalBinary returns the wire format of the option.
+func (l AddressList) MarshalBinary() ([]byte, error) {
+	return l.Marshal()
+}
+
+// UnmarshalBinary sets the option to the one parsed from data, reusing the
+// storage kept by Reset.
+func (rr *RR) UnmarshalBinary(data []byte) error {
+	return unmarshalAddressList(&rr.AddressList, data, RecordRoute, LooseSourceRecordRoute, StrictSourceRecordRoute)
+}
+
+// UnmarshalBinary sets the option to the one parsed from data, reusing the
+// storage kept by Reset.
+func (l *LSRR) UnmarshalBinary(data []byte) error {
+	return unmarshalAddressList(&l.AddressList, data, LooseSourceRecordRoute)
+}
+
+// UnmarshalBinary sets the option to the one parsed from data, reusing the
+// storage kept by Reset.
+func (s *SSRR) UnmarshalBinary(data []byte) error {
+	return unmarshalAddressList(&s.AddressList, data, StrictSourceRecordRoute)
+}
+
+// UnmarshalBinary sets the option to the one parsed from data, reusing the
+// storage kept by Reset.
+func (s *SDB) UnmarshalBinary(data []byte) error {
+	return unmarshalAddressList(&s.AddressList, data, SDBOption)
+}
+
+// UnmarshalBinary sets the option to the one parsed from data, reusing the
+// storage kept by Reset.
+func (u *UMP) UnmarshalBinary(data []byte) error {
+	return unmarshalAddressList(&u.AddressList, data, UMPOption)
+}
+
+// MarshalBinary returns the wire format of the option.
+func (u UInt16Option) MarshalBinary() ([]byte, error) {
+	return u.Marshal()
+}
+
+// UnmarshalBinary sets the option to the one parsed from data. Any option
+// type is accepted.
+func (u *UInt16Option) UnmarshalBinary(data []byte) error {
+	o, err := unmarshalBinary(data, ParseUInt16Option)
+	if err != nil {
+		return err
+	}
+	*u = o.(UInt16Option)
+	return nil
+}
+
+// MarshalBinary returns the wire format of the option.
+func (s StreamID) MarshalBinary() ([]byte, error) {
+	return s.Marshal()
+}
+
+// UnmarshalBinary sets the option to the one parsed from data.
+func (s *StreamID) UnmarshalBinary(data []byte) error {
+	o, err := unmarshalBinary(data, parseStreamID, StreamIdentifier)
+	if err != nil {
+		return err
+	}
+	*s = o.(StreamID)
+	return nil
+}
+
+// MarshalBinary returns the wire format of the option.
+func (ts TS) MarshalBinary() ([]byte, error) {
+	return ts.Marshal()
+}
+
+// UnmarshalBinary sets the option to the one parsed from data, reusing the
+// storage kept by Reset.
+func (ts *TS) UnmarshalBinary(data []byte) error {
+	if err := checkBinary(data, InternetTimestamp); err != nil {
+		return err
+	}
+	if err := checkWholeOption(data, 4); err != nil {
+		return err
+	}
+	return ts.decode(data)
+}
+
+// MarshalBinary returns the wire format of the option.
+func (ra RouterAlert) MarshalBinary() ([]byte, error) {
+	return ra.Marshal()
+}
+
+// UnmarshalBinary sets the option to the one parsed from data.
+func (ra *RouterAlert) UnmarshalBinary(data []byte) error {
+	o, err := unmarshalBinary(data, parseRouterAlert, RouterAlertOption)
+	if err != nil {
+		return err
+	}
+	*ra = o.(RouterAlert)
+	return nil
+}
+
+// MarshalBinary returns the wire format of the option.
+func (c CIPSO) MarshalBinary() ([]byte, error) {
+	return c.Marshal()
+}
+
+// UnmarshalBinary sets the option to the one parsed from data.
+func (c *CIPSO) UnmarshalBinary(data []byte) error {
+	o, err := unmarshalBinary(data, parseCIPSO, CommercialSecurity)
+	if err != nil {
+		return err
+	}
+	*c = o.(CIPSO)
+	return nil
+}
+
+// MarshalBinary returns the wire format of the option.
+func (es ESec) MarshalBinary() ([]byte, error) {
+	return es.Marshal()
+}
+
+// UnmarshalBinary sets the option to the one parsed from data.
+func (es *ESec) UnmarshalBinary(data []byte) error {
+	o, err := unmarshalBinary(data, parseExtendedSecurity, ExtendedSecurity)
+	if err != nil {
+		return err
+	}
+	*es = o.(ESec)
+	return nil
+}
+
+// MarshalBinary returns the wire format of the option.
+func (m MTUProbe) MarshalBinary() ([]byte, error) {
+	return m.Marshal()
+}
+
+// UnmarshalBinary sets the option to the one parsed from data.
+func (m *MTUProbe) UnmarshalBinary(data []byte) error {
+	o, err := unmarshalBinary(data, parseMTUProbe, MTUProbeOption)
+	if err != nil {
+		return err
+	}
+	*m = o.(MTUProbe)
+	return nil
+}
+
+// MarshalBinary returns the wire format of the option.
+func (m MTUReply) MarshalBinary() ([]byte, error) {
+	return m.Marshal()
+}
+
+// UnmarshalBinary sets the option to the one parsed from data.
+func (m *MTUReply) UnmarshalBinary(data []byte) error {
+	o, err := unmarshalBinary(data, parseMTUReply, MTUReplyOption)
+	if err != nil {
+		return err
+	}
+	*m = o.(MTUReply)
+	return nil
+}
+
+// MarshalBinary returns the wire format of the option.
+func (tr Traceroute) MarshalBinary() ([]byte, error) {
+	return tr.Marshal()
+}
+
+// UnmarshalBinary sets the option to the one parsed from data.
+func (tr *Traceroute) UnmarshalBinary(data []byte) error {
+	o, err := unmarshalBinary(data, parseTraceroute, TracerouteOption)
+	if err != nil {
+		return err
+	}
+	*tr = o.(Traceroute)
+	return nil
+}
+
+// MarshalBinary returns the wire format of the option.
+func (qs QuickStart) MarshalBinary() ([]byte, error) {
+	return qs.Marshal()
+}
+
+// UnmarshalBinary sets the option to the one parsed from data.
+func (qs *QuickStart) UnmarshalBinary(data []byte) error {
+	o, err := unmarshalBinary(data, parseQuickStart, QuickStartOption)
+	if err != nil {
+		return err
+	}
+	*qs = o.(QuickStart)
+	return nil
+}
+
+// MarshalBinary returns the wire format of the option.
+func (exp Experimental) MarshalBinary() ([]byte, error) {
+	return exp.Marshal()
+}
+
+// UnmarshalBinary sets the option to the one parsed from data.
+func (exp *Experimental) UnmarshalBinary(data []byte) error {
+	o, err := unmarshalBinary(data, parseExperimental, ExperimentalOption30, ExperimentalOption94, ExperimentalOption158, ExperimentalOption222)
+	if err != nil {
+		return err
+	}
+	*exp = o.(Experimental)
+	return nil
+}
+
+// MarshalBinary returns the wire format of the option.
+func (raw RawOption) MarshalBinary() ([]byte, error) {
+	return raw.Marshal()
+}
+
+// UnmarshalBinary sets the option to the one parsed from data.
+func (raw *RawOption) UnmarshalBinary(data []byte) error {
+	o, err := unmarshalBinary(data, parseRaw)
+	if err != nil {
+		return err
+	}
+	*raw = o.(RawOption)
+	return nil
+}
@@ -0,0 +1,138 @@
+package ipv4opt_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func TestOptionsBinaryGob(t *testing.T) {
+	var data []byte
+	data = append(data, ipv4opt.NoOperation)
+	data = append(data, sidTest...)
+	data = append(data, rrEmptyTest[:11]...)
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(ops); err != nil {
+		t.Fatal(err)
+	}
+	var decoded ipv4opt.Options
+	if err := gob.NewDecoder(&buf).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	got, err := decoded.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("Expected(%v), Got(%v)", data, got)
+	}
+}
+
+func TestOptionUnmarshalBinary(t *testing.T) {
+	tests := []struct {
+		data []byte
+		err  error
+	}{
+		{rrEmptyTest[:11], nil},
+		{[]byte{131, 7, 4, 0, 0, 0, 0}, nil},
+		{sidTest, ipv4opt.ErrOptionType},
+		{rrEmptyTest, ipv4opt.ErrInvalidOptionLength},
+		{nil, ipv4opt.ErrTruncatedOption},
+	}
+	for _, test := range tests {
+		var rr ipv4opt.RR
+		err := rr.UnmarshalBinary(test.data)
+		if !errors.Is(err, test.err) {
+			t.Fatalf("Expected(%v), Got(%v)", test.err, err)
+		}
+		if err != nil {
+			continue
+		}
+		got, err := rr.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, test.data) {
+			t.Fatalf("Expected(%v), Got(%v)", test.data, got)
+		}
+	}
+}
+
+func TestUnmarshalBinaryReuse(t *testing.T) {
+	pool := sync.Pool{New: func() interface{} { return new(ipv4opt.RR) }}
+	rr := pool.Get().(*ipv4opt.RR)
+	if err := rr.UnmarshalBinary(rrTest[:39]); err != nil {
+		t.Fatal(err)
+	}
+	kept := *rr
+	routes := append([]ipv4opt.Route(nil), kept.Routes...)
+	if err := rr.UnmarshalBinary(rrEmptyTest[:11]); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(kept.Routes, routes) {
+		t.Fatalf("Unmarshal changed a copy of an option that was not reset, Expected(%v), Got(%v)", routes, kept.Routes)
+	}
+	rr.Reset()
+	pool.Put(rr)
+
+	rr = pool.Get().(*ipv4opt.RR)
+	allocs := testing.AllocsPerRun(100, func() {
+		rr.Reset()
+		if err := rr.UnmarshalBinary(rrEmptyTest[:11]); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Fatalf("Reused option allocated, Expected(0), Got(%v)", allocs)
+	}
+	if len(rr.Routes) != 2 || rr.Length() != 11 {
+		t.Fatalf("Wrong reused option, Got(%v)", rr)
+	}
+
+	var ts ipv4opt.TS
+	if err := ts.UnmarshalBinary(tsTest); err != nil {
+		t.Fatal(err)
+	}
+	allocs = testing.AllocsPerRun(100, func() {
+		ts.Reset()
+		if err := ts.UnmarshalBinary(tsTest2); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Fatalf("Reused option allocated, Expected(0), Got(%v)", allocs)
+	}
+	if len(ts.Stamps) != 4 || ts.Flags != ipv4opt.TSAndAddr {
+		t.Fatalf("Wrong reused option, Got(%v)", ts)
+	}
+}
+
+func TestUInt16OptionBinary(t *testing.T) {
+	data := []byte{202, 4, 0x12, 0x34}
+	var u ipv4opt.UInt16Option
+	if err := u.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if u.Type() != 202 || u.Value != 0x1234 {
+		t.Fatalf("Wrong option, Got(%v %v)", u.Type(), u.Value)
+	}
+	b, err := u.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, data) {
+		t.Fatalf("Expected(%v), Got(%v)", data, b)
+	}
+	if err := u.UnmarshalBinary([]byte{202, 5, 0, 0, 0}); !errors.Is(err, ipv4opt.ErrInvalidOptionLength) {
+		t.Fatalf("Wrong error, Expected(%v), Got(%v)", ipv4opt.ErrInvalidOptionLength, err)
+	}
+}
@@ -0,0 +1,152 @@
+package ipv4opt
+
+const (
+	// CIPSORestrictedBitmap is the CIPSO tag carrying categories as a bitmap.
+	CIPSORestrictedBitmap = 1
+	// CIPSOEnumerated is the CIPSO tag carrying a list of categories.
+	CIPSOEnumerated = 2
+	// CIPSORanged is the CIPSO tag carrying ranges of categories.
+	CIPSORanged = 5
+)
+
+const cipsoHeaderLen = 6
+
+// CIPSORange is a range of categories from a ranged CIPSO tag.
+type CIPSORange struct {
+	High uint16
+	Low  uint16
+}
+
+// CIPSOTag is a single tag from a CIPSO option. Level and Categories are
+// decoded for restricted bitmap and enumerated tags, Level and Ranges for
+// ranged tags. Data holds the tag contents after its type and length.
+type CIPSOTag struct {
+	Type       uint8
+	Level      uint8
+	Categories []uint16
+	Ranges     []CIPSORange
+	Data       []byte
+}
+
+// CIPSO is the ipv4 commercial security option
+type CIPSO struct {
+	option
+	DOI  uint32
+	Tags []CIPSOTag
+}
+
+func parseCIPSO(data []byte) (IPOption, error) {
+	var c CIPSO
+	length, err := optionLength(data, cipsoHeaderLen)
+	if err != nil {
+		return nil, err
+	}
+	c.option.otype = CommercialSecurity
+	c.option.length = length
+	c.option.data = make([]byte, c.option.length, c.option.length)
+	copy(c.option.data, data)
+
+	c.DOI |= uint32(data[2]) << 24
+	c.DOI |= uint32(data[3]) << 16
+	c.DOI |= uint32(data[4]) << 8
+	c.DOI |= uint32(data[5])
+
+	tags := c.option.data[cipsoHeaderLen:]
+	for len(tags) > 0 {
+		if len(tags) < 2 || int(tags[1]) < 2 || int(tags[1]) > len(tags) {
+			return nil, ErrInvalidOptionLength
+		}
+		tag, err := parseCIPSOTag(tags[0], tags[2:tags[1]])
+		if err != nil {
+			return nil, err
+		}
+		c.Tags = append(c.Tags, tag)
+		tags = tags[tags[1]:]
+	}
+	return c, nil
+}
+
+func parseCIPSOTag(t byte, data []byte) (CIPSOTag, error) {
+	tag := CIPSOTag{Type: t, Data: data}
+	switch t {
+	case CIPSORestrictedBitmap, CIPSOEnumerated, CIPSORanged:
+		if len(data) < 2 {
+			return tag, ErrInvalidOptionLength
+		}
+		tag.Level = data[1]
+	default:
+		return tag, nil
+	}
+	cats := data[2:]
+	switch t {
+	case CIPSORestrictedBitmap:
+		for i, b := range cats {
+			for bit := 0; bit < 8; bit++ {
+				if b&(0x80>>uint(bit)) != 0 {
+					tag.Categories = append(tag.Categories, uint16(i*8+bit))
+				}
+			}
+		}
+	case CIPSOEnumerated:
+		if len(cats)%2 != 0 {
+			return tag, ErrInvalidOptionLength
+		}
+		for i := 0; i < len(cats); i += 2 {
+			tag.Categories = append(tag.Categories, uint16(cats[i])<<8|uint16(cats[i+1]))
+		}
+	case CIPSORanged:
+		// The low end of the last range may be left out when it is zero.
+		if len(cats)%2 != 0 {
+			return tag, ErrInvalidOptionLength
+		}
+		for i := 0; i < len(cats); i += 4 {
+			var r CIPSORange
+			r.High = uint16(cats[i])<<8 | uint16(cats[i+1])
+			if i+3 < len(cats) {
+				r.Low = uint16(cats[i+2])<<8 | uint16(cats[i+3])
+			}
+			tag.Ranges = append(tag.Ranges, r)
+		}
+	}
+	return tag, nil
+}
+
+// encode returns the wire format of the tag.
+func (tag CIPSOTag) encode() []byte {
+	var body []byte
+	switch tag.Type {
+	case CIPSORestrictedBitmap:
+		body = []byte{0, tag.Level}
+		for _, c := range tag.Categories {
+			i := 2 + int(c/8)
+			for len(body) <= i {
+				body = append(body, 0)
+			}
+			body[i] |= 0x80 >> (c % 8)
+		}
+	case CIPSOEnumerated:
+		body = []byte{0, tag.Level}
+		for _, c := range tag.Categories {
+			body = append(body, byte(c>>8), byte(c))
+		}
+	case CIPSORanged:
+		body = []byte{0, tag.Level}
+		for _, r := range tag.Ranges {
+			body = append(body, byte(r.High>>8), byte(r.High), byte(r.Low>>8), byte(r.Low))
+		}
+	default:
+		body = tag.Data
+	}
+	return append([]byte{tag.Type, byte(2 + len(body))}, body...)
+}
+
+// encode appends the wire format of the option built from its fields to dst.
+func (c CIPSO) encode(dst []byte) []byte {
+	start := len(dst)
+	b := append(dst, CommercialSecurity, 0, byte(c.DOI>>24), byte(c.DOI>>16), byte(c.DOI>>8), byte(c.DOI))
+	for _, tag := range c.Tags {
+		b = append(b, tag.encode()...)
+	}
+	b[start+1] = byte(len(b) - start)
+	return b
+}
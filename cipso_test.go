@@ -0,0 +1,49 @@
+package ipv4opt_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+var cipsoTest = []byte{
+	134, 25, 0, 0, 0, 3,
+	1, 5, 0, 7, 0x41,
+	2, 6, 0, 5, 0x01, 0x00,
+	5, 8, 0, 2, 0, 20, 0, 10,
+}
+
+func TestCIPSO(t *testing.T) {
+	ops, err := ipv4opt.Parse(cipsoTest)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	c, ok := ops[0].(ipv4opt.CIPSO)
+	if !ok {
+		t.Fatalf("Wrong option, Expected(CIPSO), Got(%T)", ops[0])
+	}
+	if c.Type() != ipv4opt.CommercialSecurity || c.Length() != 25 || c.DOI != 3 {
+		t.Fatalf("Wrong CIPSO option, Got(%v)", c)
+	}
+	if len(c.Tags) != 3 {
+		t.Fatalf("Wrong number of tags, Expected(%v), Got(%v)", 3, len(c.Tags))
+	}
+	if c.Tags[0].Level != 7 || !reflect.DeepEqual(c.Tags[0].Categories, []uint16{1, 7}) {
+		t.Fatalf("Wrong bitmap tag, Got(%v)", c.Tags[0])
+	}
+	if c.Tags[1].Level != 5 || !reflect.DeepEqual(c.Tags[1].Categories, []uint16{256}) {
+		t.Fatalf("Wrong enumerated tag, Got(%v)", c.Tags[1])
+	}
+	ranges := []ipv4opt.CIPSORange{{High: 20, Low: 10}}
+	if c.Tags[2].Level != 2 || !reflect.DeepEqual(c.Tags[2].Ranges, ranges) {
+		t.Fatalf("Wrong ranged tag, Got(%v)", c.Tags[2])
+	}
+	b, err := c.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(b, cipsoTest) {
+		t.Fatalf("Wrong marshaled data, Expected(%v), Got(%v)", cipsoTest, b)
+	}
+}
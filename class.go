@@ -0,0 +1,61 @@
+package ipv4opt
+
+import "fmt"
+
+// OptionClass is the class of an option, from bits 1 and 2 of its type.
+type OptionClass uint8
+
+const (
+	// ClassControl is the class of control options.
+	ClassControl OptionClass = 0
+	// ClassReserved1 is reserved for future use.
+	ClassReserved1 OptionClass = 1
+	// ClassDebugging is the class of debugging and measurement options.
+	ClassDebugging OptionClass = 2
+	// ClassReserved3 is reserved for future use.
+	ClassReserved3 OptionClass = 3
+)
+
+func (c OptionClass) String() string {
+	switch c {
+	case ClassControl:
+		return "Control"
+	case ClassReserved1:
+		return "Reserved1"
+	case ClassDebugging:
+		return "Debugging"
+	case ClassReserved3:
+		return "Reserved3"
+	}
+	return fmt.Sprintf("OptionClass(%d)", uint8(c))
+}
+
+// Copied reports whether the copied flag of the option type is set, meaning
+// the option must be copied into every fragment of a datagram.
+func (t OptionType) Copied() bool {
+	return t&0x80 != 0
+}
+
+// Class returns the option class encoded in the option type.
+func (t OptionType) Class() OptionClass {
+	return OptionClass(t>>5) & 0x03
+}
+
+// Number returns the option number, the low 5 bits of the option type.
+func (t OptionType) Number() uint8 {
+	return uint8(t) & 0x1f
+}
+
+// CopiedOnFragment returns the options that must be copied into every
+// fragment of a datagram, which are those whose type has the copied flag
+// set. The result is what goes into the headers of fragments after the
+// first; use Marshal to write it with the required padding.
+func (o Options) CopiedOnFragment() Options {
+	var copied Options
+	for _, opt := range o {
+		if opt.Type().Copied() {
+			copied = append(copied, opt)
+		}
+	}
+	return copied
+}
@@ -0,0 +1,60 @@
+package ipv4opt_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func TestOptionTypeFields(t *testing.T) {
+	tests := []struct {
+		otype  ipv4opt.OptionType
+		copied bool
+		class  ipv4opt.OptionClass
+		number uint8
+	}{
+		{ipv4opt.EndOfOptionList, false, ipv4opt.ClassControl, 0},
+		{ipv4opt.NoOperation, false, ipv4opt.ClassControl, 1},
+		{ipv4opt.Security, true, ipv4opt.ClassControl, 2},
+		{ipv4opt.LooseSourceRecordRoute, true, ipv4opt.ClassControl, 3},
+		{ipv4opt.RecordRoute, false, ipv4opt.ClassControl, 7},
+		{ipv4opt.InternetTimestamp, false, ipv4opt.ClassDebugging, 4},
+		{ipv4opt.TracerouteOption, false, ipv4opt.ClassDebugging, 18},
+		{ipv4opt.RouterAlertOption, true, ipv4opt.ClassControl, 20},
+		{0xff, true, ipv4opt.ClassReserved3, 31},
+	}
+	for _, test := range tests {
+		if test.otype.Copied() != test.copied {
+			t.Fatalf("Wrong copied flag for %v, Expected(%v), Got(%v)", test.otype, test.copied, test.otype.Copied())
+		}
+		if test.otype.Class() != test.class {
+			t.Fatalf("Wrong class for %v, Expected(%v), Got(%v)", test.otype, test.class, test.otype.Class())
+		}
+		if test.otype.Number() != test.number {
+			t.Fatalf("Wrong number for %v, Expected(%v), Got(%v)", test.otype, test.number, test.otype.Number())
+		}
+	}
+}
+
+func TestCopiedOnFragment(t *testing.T) {
+	var data []byte
+	data = append(data, ipv4opt.NoOperation)
+	data = append(data, 131, 7, 4, 10, 0, 0, 1)
+	data = append(data, 7, 7, 4, 0, 0, 0, 0)
+	data = append(data, 148, 4, 0, 0)
+	data = append(data, 68, 8, 5, 0, 0, 0, 0, 0)
+	data = append(data, ipv4opt.EndOfOptionList)
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	b, err := ops.CopiedOnFragment().Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []byte{131, 7, 4, 10, 0, 0, 1, 148, 4, 0, 0, 0}
+	if !bytes.Equal(b, expected) {
+		t.Fatalf("Expected(%v), Got(%v)", expected, b)
+	}
+}
@@ -0,0 +1,151 @@
+// Command ipv4opt-extract reads a pcap or pcapng capture and reports the IPv4
+// packets in it that carry options, printing one line per packet with the
+// packet number, the options in hex and their decoded form.
+//
+// Usage:
+//
+//	ipv4opt-extract [-corpus dir] capture.pcap
+//
+// With -corpus, the options of every packet are also written to dir in the
+// format of a Go fuzz corpus, ready to be copied into testdata/fuzz/FuzzParse.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+	ipv4opt "github.com/rhansen2/ipv4optparser"
+)
+
+// pcapngMagic is the block type of the section header that starts a pcapng
+// file.
+var pcapngMagic = []byte{0x0a, 0x0d, 0x0d, 0x0a}
+
+// packetSource is implemented by the pcap and pcapng readers.
+type packetSource interface {
+	ReadPacketData() ([]byte, gopacket.CaptureInfo, error)
+	LinkType() layers.LinkType
+}
+
+func main() {
+	corpus := flag.String("corpus", "", "write the options of each packet to `dir` as a fuzz corpus")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [-corpus dir] capture.pcap\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	f, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	if err := extract(f, w, *corpus); err != nil {
+		w.Flush()
+		log.Fatal(err)
+	}
+}
+
+// openCapture returns a reader for the pcap or pcapng capture in r.
+func openCapture(r io.Reader) (packetSource, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(pcapngMagic))
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(magic, pcapngMagic) {
+		return pcapgo.NewNgReader(br, pcapgo.DefaultNgReaderOptions)
+	}
+	return pcapgo.NewReader(br)
+}
+
+// extract writes a report of the options of every IPv4 packet in the capture
+// read from r to w and, if dir is not empty, writes them to dir as a fuzz
+// corpus.
+func extract(r io.Reader, w io.Writer, dir string) error {
+	src, err := openCapture(r)
+	if err != nil {
+		return err
+	}
+	for n := 1; ; n++ {
+		data, _, err := src.ReadPacketData()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		opts := optionsOf(data, src.LinkType())
+		if opts == nil {
+			continue
+		}
+		report := "error: "
+		ops, err := ipv4opt.Parse(opts)
+		if err != nil {
+			report += err.Error()
+		} else {
+			report = ops.String()
+		}
+		if _, err := fmt.Fprintf(w, "%d %x %s\n", n, opts, report); err != nil {
+			return err
+		}
+		if dir != "" {
+			if err := writeCorpusEntry(dir, opts); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// optionsOf returns the options of the IPv4 packet in the frame data, or nil
+// if data does not hold an IPv4 packet with options. The IPv4 header is
+// located through gopacket, falling back to the payload of the link layer so
+// that packets whose options gopacket rejects are still reported.
+func optionsOf(data []byte, lt layers.LinkType) []byte {
+	pkt := gopacket.NewPacket(data, lt, gopacket.NoCopy)
+	var ip []byte
+	switch {
+	case pkt.Layer(layers.LayerTypeIPv4) != nil:
+		ip = pkt.Layer(layers.LayerTypeIPv4).LayerContents()
+	case pkt.LinkLayer() != nil:
+		ip = pkt.LinkLayer().LayerPayload()
+	default:
+		ip = data
+	}
+	if len(ip) < 20 || ip[0]>>4 != 4 {
+		return nil
+	}
+	ihl := int(ip[0]&0x0f) * 4
+	if ihl <= 20 || ihl > len(ip) {
+		return nil
+	}
+	return ip[20:ihl]
+}
+
+// writeCorpusEntry writes opts to dir in the Go fuzz corpus format, named
+// after its hash like the entries written by go test -fuzz.
+func writeCorpusEntry(dir string, opts []byte) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	sum := sha256.Sum256(opts)
+	name := filepath.Join(dir, hex.EncodeToString(sum[:8]))
+	entry := fmt.Sprintf("go test fuzz v1\n[]byte(%q)\n", opts)
+	return os.WriteFile(name, []byte(entry), 0o644)
+}
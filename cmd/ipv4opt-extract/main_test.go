@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+// ethernetIPv4 returns an Ethernet frame carrying an IPv4 header with opts.
+func ethernetIPv4(opts []byte) []byte {
+	frame := []byte{
+		0, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0x08, 0x00,
+		0x40 | byte(5+len(opts)/4), 0, 0, byte(20 + len(opts)), 0, 1, 0, 0, 64, 1, 0, 0,
+		192, 0, 2, 1, 192, 0, 2, 2,
+	}
+	return append(frame, opts...)
+}
+
+func TestExtract(t *testing.T) {
+	var capture bytes.Buffer
+	pw := pcapgo.NewWriter(&capture)
+	if err := pw.WriteFileHeader(65535, layers.LinkTypeEthernet); err != nil {
+		t.Fatal(err)
+	}
+	frames := [][]byte{
+		ethernetIPv4(nil),
+		ethernetIPv4([]byte{148, 4, 0, 0}),
+		ethernetIPv4([]byte{7, 200, 4, 0}),
+	}
+	for _, frame := range frames {
+		ci := gopacket.CaptureInfo{Timestamp: time.Unix(0, 0), CaptureLength: len(frame), Length: len(frame)}
+		if err := pw.WritePacket(ci, frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dir := t.TempDir()
+	var out bytes.Buffer
+	if err := extract(&capture, &out, dir); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	expected := []string{"2 94040000 RTRALT{0}", "3 07c80400 error: "}
+	if len(lines) != len(expected) {
+		t.Fatalf("Wrong number of lines, Expected(%v), Got(%v)", expected, lines)
+	}
+	for i, line := range lines {
+		if !strings.HasPrefix(line, expected[i]) {
+			t.Fatalf("Wrong report line, Expected(%v), Got(%v)", expected[i], line)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Wrong number of corpus entries, Expected(%v), Got(%v)", 2, len(entries))
+	}
+	b, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(b), "go test fuzz v1\n[]byte(") {
+		t.Fatalf("Wrong corpus entry format, Got(%q)", b)
+	}
+}
@@ -0,0 +1,101 @@
+// Command ipv4opt decodes IPv4 options given as hex or base64 and prints
+// them as text or JSON.
+//
+// Usage:
+//
+//	ipv4opt [-header] [-json] [bytes...]
+//
+// The bytes are read from the arguments or, if there are none, from standard
+// input. Hex may be written as one stream, as copied from Wireshark, or with
+// spaces, colons or a 0x prefix between bytes. Input that is not hex is
+// decoded as base64. With -header the bytes are a full IPv4 header or packet
+// and the options are taken from it according to its IHL field.
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	ipv4opt "github.com/rhansen2/ipv4optparser"
+)
+
+var errNoInput = errors.New("no option bytes given")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "ipv4opt:", err)
+		os.Exit(1)
+	}
+}
+
+// run decodes the options given by args, or read from stdin if args holds no
+// bytes, and prints them to stdout.
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("ipv4opt", flag.ContinueOnError)
+	header := fs.Bool("header", false, "the input is a full IPv4 header or packet")
+	asJSON := fs.Bool("json", false, "print the options as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	input := strings.Join(fs.Args(), " ")
+	if fs.NArg() == 0 {
+		b, err := io.ReadAll(stdin)
+		if err != nil {
+			return err
+		}
+		input = string(b)
+	}
+	data, err := decodeInput(input)
+	if err != nil {
+		return err
+	}
+	var ops ipv4opt.Options
+	if *header {
+		ops, err = ipv4opt.ParseFromPacket(data)
+	} else {
+		ops, err = ipv4opt.Parse(data)
+	}
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		b, err := json.MarshalIndent(ops, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(stdout, "%s\n", b)
+		return err
+	}
+	for _, opt := range ops {
+		if _, err := fmt.Fprintln(stdout, ipv4opt.Options{opt}.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeInput returns the bytes written in s as hex or, failing that, as
+// base64.
+func decodeInput(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, errNoInput
+	}
+	h := strings.NewReplacer("0x", "", "0X", "", ":", "", " ", "", "\t", "", "\n", "", "\r", "").Replace(s)
+	if b, err := hex.DecodeString(h); err == nil {
+		return b, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+	if err != nil {
+		return nil, fmt.Errorf("input is neither hex nor base64: %w", err)
+	}
+	return bytes.Clone(b), nil
+}
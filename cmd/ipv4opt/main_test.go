@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		output string
+		err    error
+	}{
+		{"hex stream", []string{"9404000001000000"}, "", "RTRALT{0}\nNOP\nEOL\n", nil},
+		{"separated hex", []string{"94:04:00:00"}, "", "RTRALT{0}\n", nil},
+		{"prefixed hex", []string{"0x94", "0x04", "0x00", "0x00"}, "", "RTRALT{0}\n", nil},
+		{"base64", []string{"lAQAAA=="}, "", "RTRALT{0}\n", nil},
+		{"stdin", nil, "94 04 00 00\n", "RTRALT{0}\n", nil},
+		{"json", []string{"-json", "94040000"}, "", `"Type": "RTRALT"`, nil},
+		{"empty", nil, "", "", errNoInput},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		err := run(test.args, strings.NewReader(test.stdin), &out)
+		if !errors.Is(err, test.err) {
+			t.Fatalf("%v: Wrong error, Expected(%v), Got(%v)", test.name, test.err, err)
+		}
+		if !strings.Contains(out.String(), test.output) {
+			t.Fatalf("%v: Wrong output, Expected(%q), Got(%q)", test.name, test.output, out.String())
+		}
+	}
+}
+
+func TestRunHeader(t *testing.T) {
+	var out bytes.Buffer
+	err := run([]string{"-header", "460000200001000040010000c0a80001c0a8000288041234"}, nil, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "SID{4660}\n" {
+		t.Fatalf("Wrong output, Expected(%q), Got(%q)", "SID{4660}\n", out.String())
+	}
+}
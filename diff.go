@@ -0,0 +1,165 @@
+package ipv4opt
+
+import (
+	"fmt"
+	"slices"
+)
+
+// DiffEntry is a byte that differs between two option buffers.
+type DiffEntry struct {
+	Offset int
+	A, B   byte
+}
+
+// paddingMask reports for every byte of opts whether it is padding: a
+// NoOperation or EndOfOptionList byte, or anything after the end of the
+// option list.
+func paddingMask(opts []byte, n int) []bool {
+	mask := make([]bool, n)
+	l := Lazy(opts)
+	l.index()
+	end := len(opts)
+	for i, t := range l.types {
+		off := l.offsets[i]
+		if t == EndOfOptionList {
+			end = off
+			break
+		}
+		if t == NoOperation {
+			mask[off] = true
+		}
+	}
+	for i := end; i < n; i++ {
+		mask[i] = true
+	}
+	return mask
+}
+
+// ByteDiff returns the bytes that differ between the option buffers a and b,
+// ignoring differences where both buffers hold padding. Bytes past the end of
+// the shorter buffer compare as zero.
+func ByteDiff(a, b []byte) []DiffEntry {
+	n := len(a)
+	if len(b) > n {
+		n = len(b)
+	}
+	padA, padB := paddingMask(a, n), paddingMask(b, n)
+	var diff []DiffEntry
+	for i := 0; i < n; i++ {
+		if padA[i] && padB[i] {
+			continue
+		}
+		var x, y byte
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			diff = append(diff, DiffEntry{Offset: i, A: x, B: y})
+		}
+	}
+	return diff
+}
+
+// ChangeKind is the kind of a Change between two option lists.
+type ChangeKind uint8
+
+const (
+	// OptionAdded is an option present only in the second list.
+	OptionAdded ChangeKind = iota
+	// OptionRemoved is an option present only in the first list.
+	OptionRemoved
+	// OptionModified is an option present in both lists with different
+	// contents.
+	OptionModified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case OptionAdded:
+		return "Added"
+	case OptionRemoved:
+		return "Removed"
+	case OptionModified:
+		return "Modified"
+	}
+	return fmt.Sprintf("ChangeKind(%d)", uint8(k))
+}
+
+// Change is a difference between two option lists found by Diff.
+type Change struct {
+	Kind ChangeKind
+	Type OptionType
+	// A and B are the option in the first and second list. A is nil for
+	// added options and B is nil for removed ones.
+	A, B IPOption
+	// AppendedRoutes holds the routes recorded in B after those recorded in
+	// A, when B is a route option that extends the route of A.
+	AppendedRoutes []Route
+	// AppendedStamps holds the stamps recorded in B after those recorded in
+	// A, when B is a timestamp option that extends the stamps of A.
+	AppendedStamps []Stamp
+}
+
+// Diff reports the options added, removed and modified between a and b.
+// Options are paired by type in order of appearance, so the second record
+// route of a is compared with the second record route of b. Padding is
+// ignored. For route and timestamp options, the entries that b has recorded
+// beyond those of a are reported when the entries of a are a prefix of them,
+// as when a router has processed the option.
+func Diff(a, b Options) []Change {
+	a, b = a.withoutPadding(), b.withoutPadding()
+	matched := make([]bool, len(b))
+	var changes []Change
+	for _, x := range a {
+		j := -1
+		for k, y := range b {
+			if !matched[k] && y.Type() == x.Type() {
+				j = k
+				break
+			}
+		}
+		if j < 0 {
+			changes = append(changes, Change{Kind: OptionRemoved, Type: x.Type(), A: x})
+			continue
+		}
+		matched[j] = true
+		if optionsEqual(x, b[j]) {
+			continue
+		}
+		changes = append(changes, modified(x, b[j]))
+	}
+	for j, y := range b {
+		if !matched[j] {
+			changes = append(changes, Change{Kind: OptionAdded, Type: y.Type(), B: y})
+		}
+	}
+	return changes
+}
+
+// modified returns the Change for x being modified into y.
+func modified(x, y IPOption) Change {
+	c := Change{Kind: OptionModified, Type: x.Type(), A: x, B: y}
+	if rx, ok := asRR(x); ok {
+		if ry, ok := asRR(y); ok {
+			c.AppendedRoutes = appended(rx.Recorded(), ry.Recorded())
+		}
+	}
+	if tx, ok := x.(TS); ok {
+		if ty, ok := y.(TS); ok && tx.Flags == ty.Flags {
+			c.AppendedStamps = appended(tx.Recorded(), ty.Recorded())
+		}
+	}
+	return c
+}
+
+// appended returns the entries of y after the prefix x, or nil if x is not a
+// prefix of y.
+func appended[T comparable](x, y []T) []T {
+	if len(y) <= len(x) || !slices.Equal(x, y[:len(x)]) {
+		return nil
+	}
+	return slices.Clone(y[len(x):])
+}
@@ -0,0 +1,104 @@
+package ipv4opt_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func TestByteDiff(t *testing.T) {
+	zeroed := make([]byte, len(rrTest))
+	copy(zeroed, rrTest[:2])
+	zeroed[2] = 4
+	diff := ipv4opt.ByteDiff(zeroed, rrTest)
+	expected := 1
+	for _, b := range rrTest[3:39] {
+		if b != 0 {
+			expected++
+		}
+	}
+	if len(diff) != expected {
+		t.Fatalf("Wrong number of differences, Expected(%v), Got(%v)", expected, len(diff))
+	}
+	first := ipv4opt.DiffEntry{Offset: 2, A: 4, B: 40}
+	if diff[0] != first {
+		t.Fatalf("Wrong difference, Expected(%v), Got(%v)", first, diff[0])
+	}
+	for _, d := range diff {
+		if d.Offset < 2 || d.Offset >= 39 {
+			t.Fatalf("Difference outside of option data: %v", d)
+		}
+	}
+	padded := append(append([]byte{}, sidTest...), 0, 0, 0, 0)
+	if diff := ipv4opt.ByteDiff(padded, append(append([]byte{}, sidTest...), 0, 1)); len(diff) != 0 {
+		t.Fatalf("Unexpected differences in padding: %v", diff)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	probe := ipv4opt.Options{}
+	rr, err := ipv4opt.NewRecordRoute(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	probe = append(probe, rr)
+	ts, err := ipv4opt.NewTimestampOption(ipv4opt.TSOnly, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	probe = append(probe, ts)
+	sid, err := ipv4opt.Parse(sidTest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	probe = append(probe, sid[0])
+
+	hop1, hop2 := net.IPv4(192, 0, 2, 1), net.IPv4(192, 0, 2, 2)
+	quoted := ipv4opt.Options{ipv4opt.NoOp{}}
+	rr2 := rr
+	if err := rr2.AppendRoute(hop1); err != nil {
+		t.Fatal(err)
+	}
+	if err := rr2.AppendRoute(hop2); err != nil {
+		t.Fatal(err)
+	}
+	ts2 := ts
+	if err := ts2.Stamp(hop1, time.UnixMilli(1000)); err != nil {
+		t.Fatal(err)
+	}
+	ra, err := ipv4opt.Parse([]byte{148, 4, 0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	quoted = append(quoted, ts2, rr2, ra[0])
+
+	changes := ipv4opt.Diff(probe, quoted)
+	expected := []struct {
+		kind   ipv4opt.ChangeKind
+		otype  ipv4opt.OptionType
+		routes int
+		stamps int
+	}{
+		{ipv4opt.OptionModified, ipv4opt.RecordRoute, 2, 0},
+		{ipv4opt.OptionModified, ipv4opt.InternetTimestamp, 0, 1},
+		{ipv4opt.OptionRemoved, ipv4opt.StreamIdentifier, 0, 0},
+		{ipv4opt.OptionAdded, ipv4opt.RouterAlertOption, 0, 0},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Wrong number of changes, Expected(%v), Got(%v)", len(expected), changes)
+	}
+	for i, c := range changes {
+		e := expected[i]
+		if c.Kind != e.kind || c.Type != e.otype || len(c.AppendedRoutes) != e.routes || len(c.AppendedStamps) != e.stamps {
+			t.Fatalf("Wrong change %d, Expected(%v), Got(%+v)", i, e, c)
+		}
+	}
+	if changes[0].AppendedRoutes[1].String() != "192.0.2.2" {
+		t.Fatalf("Wrong appended route, Expected(%v), Got(%v)", hop2, changes[0].AppendedRoutes[1])
+	}
+	if len(ipv4opt.Diff(probe, probe)) != 0 {
+		t.Fatalf("Unexpected changes between identical lists: %v", ipv4opt.Diff(probe, probe))
+	}
+}
@@ -0,0 +1,15 @@
+// Package ipv4opt parses IPv4 options into usable structs and marshals them
+// back into wire format.
+//
+// Parsed options are values that may be shared between goroutines as long as
+// none of them modifies one in place. Data returns a copy of the wire format
+// of an option, and DataUnsafe returns it without copying for callers that
+// will not modify it. Methods that change an option, such as RR.AppendRoute
+// and TS.Stamp, work on their own copy of the option's slices, so the options
+// they were copied from are left untouched. Slice fields such as RR.Routes
+// share storage with every copy of an option and must not be modified in
+// place.
+//
+// A Parser is safe for concurrent use once created, and RegisterParser may be
+// called while other goroutines are parsing.
+package ipv4opt
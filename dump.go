@@ -0,0 +1,135 @@
+package ipv4opt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// dumpBytesPerLine is the largest number of bytes shown on one line of a
+// dump.
+const dumpBytesPerLine = 8
+
+// dumper writes the lines of a dump.
+type dumper struct {
+	b strings.Builder
+}
+
+// field writes a line showing the bytes of a field at offset along with its
+// meaning. Fields longer than a line are continued on the following lines.
+func (d *dumper) field(offset int, data []byte, format string, args ...interface{}) {
+	desc := fmt.Sprintf(format, args...)
+	for first := true; first || len(data) > 0; first = false {
+		n := min(len(data), dumpBytesPerLine)
+		hex := make([]string, n)
+		for i, c := range data[:n] {
+			hex[i] = fmt.Sprintf("%02x", c)
+		}
+		line := fmt.Sprintf("  %04x  %-*s  %s", offset, 3*dumpBytesPerLine-1, strings.Join(hex, " "), desc)
+		d.b.WriteString(strings.TrimRight(line, " "))
+		d.b.WriteByte('\n')
+		data, offset, desc = data[n:], offset+n, ""
+	}
+}
+
+// Dump returns an annotated hex dump of the options, in the manner of the
+// packet details of Wireshark. Each option is introduced by a line naming it,
+// followed by one line per field giving its offset in the options, its bytes
+// and its meaning.
+func (o Options) Dump() string {
+	var d dumper
+	offset := 0
+	for i, opt := range o {
+		data := dataOf(opt)
+		fmt.Fprintf(&d.b, "Option %d: %v, length %d\n", i, opt.Type(), opt.Length())
+		d.dumpOption(offset, opt, data)
+		offset += len(data)
+	}
+	return d.b.String()
+}
+
+// dumpOption writes the fields of opt, whose wire format data starts at
+// offset.
+func (d *dumper) dumpOption(offset int, opt IPOption, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	t := opt.Type()
+	d.field(offset, data[:1], "Type: %v, copied %v, class %v, number %d", t, t.Copied(), t.Class(), t.Number())
+	if isPadding(opt) || len(data) < 2 {
+		return
+	}
+	d.field(offset+1, data[1:2], "Length: %d", data[1])
+	body := data[2:]
+	at := offset + 2
+	if l, ok := asAddressList(opt); ok && len(body) >= 1 {
+		d.field(at, body[:1], "Pointer: %d", l.Pointer)
+		for i, r := range l.Routes {
+			if 5+4*i > len(body) {
+				break
+			}
+			state := "empty"
+			if i < l.recordedSlots() {
+				state = "recorded"
+			}
+			d.field(at+1+4*i, body[1+4*i:5+4*i], "Route %d: %v (%s)", i, r, state)
+		}
+		return
+	}
+	switch v := opt.(type) {
+	case TS:
+		if len(body) < 2 {
+			break
+		}
+		d.field(at, body[:1], "Pointer: %d", v.Pointer)
+		d.field(at+1, body[1:2], "Overflow: %d, Flag: %v", v.Over, v.Flags)
+		pos := 2
+		for i, s := range v.Stamps {
+			if pos+v.entryLen() > len(body) {
+				break
+			}
+			if v.Flags != TSOnly {
+				d.field(at+pos, body[pos:pos+4], "Address %d: %v", i, s.Addr)
+				pos += 4
+			}
+			d.field(at+pos, body[pos:pos+4], "Timestamp %d: %d", i, s.Time)
+			pos += 4
+		}
+		body, at = body[pos:], at+pos
+	case Sec:
+		if len(body) < 9 {
+			break
+		}
+		d.field(at, body[0:2], "Level: %v", v.Level)
+		d.field(at+2, body[2:4], "Compartment: %d", v.Compartment)
+		d.field(at+4, body[4:6], "Handling restriction: %v", v.Restriction)
+		d.field(at+6, body[6:9], "Transmission control code: %s", v.TCCString())
+		body, at = body[9:], at+9
+	case UInt16Option, StreamID, RouterAlert, MTUProbe, MTUReply:
+		if len(body) < 2 {
+			break
+		}
+		d.field(at, body[:2], "Value: %d", binary.BigEndian.Uint16(body))
+		body, at = body[2:], at+2
+	case Traceroute:
+		if len(body) < 10 {
+			break
+		}
+		d.field(at, body[0:2], "ID: %d", v.ID)
+		d.field(at+2, body[2:4], "Outbound hops: %d", v.OutboundHops)
+		d.field(at+4, body[4:6], "Return hops: %d", v.ReturnHops)
+		d.field(at+6, body[6:10], "Originator: %v", v.Originator)
+		body, at = body[10:], at+10
+	case QuickStart:
+		if len(body) < 6 {
+			break
+		}
+		d.field(at, body[:1], "Function: %d, Rate: %d", v.Function, v.Rate)
+		d.field(at+1, body[1:2], "TTL: %d", v.TTL)
+		d.field(at+2, body[2:6], "Nonce: %d", v.Nonce)
+		body, at = body[6:], at+6
+	}
+	if len(body) > 0 {
+		d.field(at, body, "Data")
+	}
+}
@@ -0,0 +1,49 @@
+package ipv4opt_test
+
+import (
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func TestDump(t *testing.T) {
+	data := []byte{
+		1,
+		148, 4, 0, 0,
+		131, 11, 8, 192, 0, 2, 1, 0, 0, 0, 0,
+		68, 12, 13, 3, 198, 51, 100, 9, 0, 0, 0, 5,
+		31, 12, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10,
+	}
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	expected := `Option 0: NoOperation(1), length 1
+  0000  01                       Type: NoOperation(1), copied false, class Control, number 1
+Option 1: RouterAlertOption(148), length 4
+  0001  94                       Type: RouterAlertOption(148), copied true, class Control, number 20
+  0002  04                       Length: 4
+  0003  00 00                    Value: 0
+Option 2: LooseSourceRecordRoute(131), length 11
+  0005  83                       Type: LooseSourceRecordRoute(131), copied true, class Control, number 3
+  0006  0b                       Length: 11
+  0007  08                       Pointer: 8
+  0008  c0 00 02 01              Route 0: 192.0.2.1 (recorded)
+  000c  00 00 00 00              Route 1: 0.0.0.0 (empty)
+Option 3: InternetTimestamp(68), length 12
+  0010  44                       Type: InternetTimestamp(68), copied false, class Debugging, number 4
+  0011  0c                       Length: 12
+  0012  0d                       Pointer: 13
+  0013  03                       Overflow: 0, Flag: TSPrespec
+  0014  c6 33 64 09              Address 0: 198.51.100.9
+  0018  00 00 00 05              Timestamp 0: 5
+Option 4: OptionType(31), length 12
+  001c  1f                       Type: OptionType(31), copied false, class Control, number 31
+  001d  0c                       Length: 12
+  001e  01 02 03 04 05 06 07 08  Data
+  0026  09 0a
+`
+	if got := ops.Dump(); got != expected {
+		t.Fatalf("Wrong dump, Expected(\n%s), Got(\n%s)", expected, got)
+	}
+}
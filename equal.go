@@ -0,0 +1,170 @@
+package ipv4opt
+
+import "bytes"
+
+type equaler interface {
+	Equal(IPOption) bool
+}
+
+// optionsEqual reports whether a and b carry the same content, using the
+// Equal method of a if it has one and comparing type and data otherwise.
+func optionsEqual(a, b IPOption) bool {
+	if e, ok := a.(equaler); ok {
+		return e.Equal(b)
+	}
+	return a.Type() == b.Type() && bytes.Equal(dataOf(a), dataOf(b))
+}
+
+// withoutPadding returns the options in the list that are not padding.
+func (o Options) withoutPadding() Options {
+	out := make(Options, 0, len(o))
+	for _, opt := range o {
+		if !isPadding(opt) {
+			out = append(out, opt)
+		}
+	}
+	return out
+}
+
+// Equal reports whether the lists hold options with the same content in the
+// same order. NoOperation and EndOfOptionList padding is ignored.
+func (o Options) Equal(other Options) bool {
+	a, b := o.withoutPadding(), other.withoutPadding()
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !optionsEqual(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal reports whether o is also an EOOList.
+func (opt EOOList) Equal(o IPOption) bool {
+	_, ok := o.(EOOList)
+	return ok
+}
+
+// Equal reports whether o is also a NoOp.
+func (opt NoOp) Equal(o IPOption) bool {
+	_, ok := o.(NoOp)
+	return ok
+}
+
+// Equal reports whether o is a Sec with the same fields.
+func (s Sec) Equal(o IPOption) bool {
+	s2, ok := o.(Sec)
+	return ok && s.Level == s2.Level && s.Compartment == s2.Compartment &&
+		s.Restriction == s2.Restriction && s.TCC == s2.TCC
+}
+
+// Equal reports whether o is a BasicSec with the same classification and
+// authorities.
+func (bs BasicSec) Equal(o IPOption) bool {
+	bs2, ok := o.(BasicSec)
+	return ok && bs.Classification == bs2.Classification && bytes.Equal(bs.Authorities, bs2.Authorities)
+}
+
+// Equal reports whether o is an ESec with the same format and info.
+func (es ESec) Equal(o IPOption) bool {
+	es2, ok := o.(ESec)
+	return ok && es.Format == es2.Format && bytes.Equal(es.Info, es2.Info)
+}
+
+// Equal reports whether o is a CIPSO option with the same DOI and tags.
+func (c CIPSO) Equal(o IPOption) bool {
+	c2, ok := o.(CIPSO)
+	return ok && bytes.Equal(c.encode(nil), c2.encode(nil))
+}
+
+// Equal reports whether o is an address list option of the same type with
+// the same pointer and routes.
+func (l AddressList) Equal(o IPOption) bool {
+	l2, ok := asAddressList(o)
+	if !ok || l.Type() != l2.Type() || l.Pointer != l2.Pointer || len(l.Routes) != len(l2.Routes) {
+		return false
+	}
+	for i := range l.Routes {
+		if l.Routes[i] != l2.Routes[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal reports whether o is a StreamID with the same ID. Padding before the
+// ID in Raw is ignored.
+func (s StreamID) Equal(o IPOption) bool {
+	s2, ok := o.(StreamID)
+	return ok && s.ID == s2.ID
+}
+
+// Equal reports whether o is a TS with the same pointer, flags, overflow and
+// stamps.
+func (ts TS) Equal(o IPOption) bool {
+	ts2, ok := o.(TS)
+	if !ok || ts.Pointer != ts2.Pointer || ts.Flags != ts2.Flags || ts.Over != ts2.Over || len(ts.Stamps) != len(ts2.Stamps) {
+		return false
+	}
+	for i := range ts.Stamps {
+		if ts.Stamps[i] != ts2.Stamps[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal reports whether o is a RouterAlert with the same value.
+func (ra RouterAlert) Equal(o IPOption) bool {
+	ra2, ok := o.(RouterAlert)
+	return ok && ra.Value == ra2.Value
+}
+
+// Equal reports whether o is an MTUProbe with the same MTU.
+func (m MTUProbe) Equal(o IPOption) bool {
+	m2, ok := o.(MTUProbe)
+	return ok && m.MTU == m2.MTU
+}
+
+// Equal reports whether o is an MTUReply with the same MTU.
+func (m MTUReply) Equal(o IPOption) bool {
+	m2, ok := o.(MTUReply)
+	return ok && m.MTU == m2.MTU
+}
+
+// Equal reports whether o is a Traceroute with the same fields.
+func (tr Traceroute) Equal(o IPOption) bool {
+	tr2, ok := o.(Traceroute)
+	return ok && tr.ID == tr2.ID && tr.OutboundHops == tr2.OutboundHops &&
+		tr.ReturnHops == tr2.ReturnHops && tr.Originator == tr2.Originator
+}
+
+// Equal reports whether o is a QuickStart with the same fields.
+func (qs QuickStart) Equal(o IPOption) bool {
+	qs2, ok := o.(QuickStart)
+	return ok && qs.Function == qs2.Function && qs.Rate == qs2.Rate &&
+		qs.TTL == qs2.TTL && qs.Nonce == qs2.Nonce
+}
+
+// Equal reports whether o is a UInt16Option of the same type with the same
+// value.
+func (u UInt16Option) Equal(o IPOption) bool {
+	u2, ok := o.(UInt16Option)
+	return ok && u.Type() == u2.Type() && u.Value == u2.Value
+}
+
+// Equal reports whether o is an Experimental option of the same type with
+// the same payload.
+func (exp Experimental) Equal(o IPOption) bool {
+	exp2, ok := o.(Experimental)
+	return ok && exp.Type() == exp2.Type() && bytes.Equal(exp.Value, exp2.Value)
+}
+
+// Equal reports whether o is a RawOption of the same type with the same
+// value.
+func (raw RawOption) Equal(o IPOption) bool {
+	raw2, ok := o.(RawOption)
+	return ok && raw.Type() == raw2.Type() && bytes.Equal(raw.Value, raw2.Value)
+}
@@ -0,0 +1,39 @@
+package ipv4opt_test
+
+import (
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func TestOptionsEqual(t *testing.T) {
+	tests := []struct {
+		a, b     []byte
+		expected bool
+	}{
+		{rrTest, rrTest, true},
+		{append([]byte{1, 1}, sidTest...), append(sidTest, 0, 0, 0, 0), true},
+		{sidTest, []byte{136, 6, 0, 0, 0x12, 0x34}, true},
+		{sidTest, []byte{136, 6, 0, 0, 0x12, 0x35}, false},
+		{[]byte{7, 7, 4, 10, 0, 0, 1}, []byte{131, 7, 4, 10, 0, 0, 1}, false},
+		{[]byte{131, 7, 4, 10, 0, 0, 1}, []byte{131, 7, 8, 10, 0, 0, 1}, false},
+		{[]byte{131, 7, 4, 10, 0, 0, 1}, []byte{1, 131, 7, 4, 10, 0, 0, 1}, true},
+		{tsTest, tsTest2, false},
+		{[]byte{222, 4, 1, 2}, []byte{222, 4, 1, 2}, true},
+		{[]byte{222, 4, 1, 2}, []byte{222, 4, 1, 3}, false},
+		{sidTest, append(sidTest, 148, 4, 0, 0), false},
+	}
+	for i, test := range tests {
+		a, err := ipv4opt.Parse(test.a)
+		if err != nil {
+			t.Fatalf("Failed to parse test data: %v", err)
+		}
+		b, err := ipv4opt.Parse(test.b)
+		if err != nil {
+			t.Fatalf("Failed to parse test data: %v", err)
+		}
+		if a.Equal(b) != test.expected || b.Equal(a) != test.expected {
+			t.Fatalf("Test %d, Expected(%v), Got(%v)", i, test.expected, a.Equal(b))
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package ipv4opt
+
+// isExperimental reports whether t is one of the RFC 4727 experimental
+// option types.
+func isExperimental(t OptionType) bool {
+	switch t {
+	case ExperimentalOption30, ExperimentalOption94, ExperimentalOption158, ExperimentalOption222:
+		return true
+	}
+	return false
+}
+
+// NewExperimental returns an experimental option of type t carrying payload.
+// t must be one of the RFC 4727 experimental types.
+func NewExperimental(t OptionType, payload []byte) (Experimental, error) {
+	if !isExperimental(t) {
+		return Experimental{}, ErrOptionType
+	}
+	exp := Experimental{Value: payload}
+	exp.option.otype = t
+	b, err := exp.Marshal()
+	if err != nil {
+		return Experimental{}, err
+	}
+	exp.option.length = len(b)
+	exp.option.data = b
+	exp.Value = b[2:]
+	return exp, nil
+}
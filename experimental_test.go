@@ -0,0 +1,46 @@
+package ipv4opt_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func TestExperimental(t *testing.T) {
+	for _, otype := range []ipv4opt.OptionType{30, 94, 158, 222} {
+		data := []byte{byte(otype), 5, 1, 2, 3}
+		ops, err := ipv4opt.Parse(data)
+		if err != nil {
+			t.Fatalf("Failed to parse test data: %v", err)
+		}
+		exp, ok := ops[0].(ipv4opt.Experimental)
+		if !ok {
+			t.Fatalf("Expected an experimental option, Got(%T)", ops[0])
+		}
+		if exp.Type() != otype || !bytes.Equal(exp.Value, data[2:]) {
+			t.Fatalf("Expected(%v), Got(%v)", data, exp)
+		}
+		built, err := ipv4opt.NewExperimental(otype, []byte{1, 2, 3})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(built.Data(), data) || built.Length() != 5 {
+			t.Fatalf("Expected(%v), Got(%v)", data, built.Data())
+		}
+	}
+	tests := []struct {
+		otype   ipv4opt.OptionType
+		payload []byte
+		err     error
+	}{
+		{ipv4opt.RecordRoute, nil, ipv4opt.ErrOptionType},
+		{ipv4opt.ExperimentalOption30, make([]byte, 39), ipv4opt.ErrOptionDataTooLarge},
+	}
+	for _, test := range tests {
+		if _, err := ipv4opt.NewExperimental(test.otype, test.payload); !errors.Is(err, test.err) {
+			t.Fatalf("Expected(%v), Got(%v)", test.err, err)
+		}
+	}
+}
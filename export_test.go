@@ -0,0 +1,19 @@
+package ipv4opt
+
+import (
+	"maps"
+	"testing"
+)
+
+// RestoreParsers puts the registered parsers back as they are now when t
+// ends, so that tests calling RegisterParser do not affect later tests.
+func RestoreParsers(t testing.TB) {
+	parsersMu.RLock()
+	saved := maps.Clone(parsers)
+	parsersMu.RUnlock()
+	t.Cleanup(func() {
+		parsersMu.Lock()
+		parsers = saved
+		parsersMu.Unlock()
+	})
+}
@@ -0,0 +1,125 @@
+package ipv4opt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrUnknownStack is returned when a stack name has no known option ordering.
+var ErrUnknownStack = fmt.Errorf("Unknown stack name")
+
+// stackOrders lists, for each stack, the order in which it emits options.
+// Options not listed are placed after the listed ones.
+//
+// The Linux order is the one __ip_options_echo in net/ipv4/ip_options.c
+// builds the options of a reply in, such as an ICMP echo reply: record
+// route, timestamp, the reversed source route and then CIPSO. Options sent
+// with IP_OPTIONS are copied as given by ip_options_build, so they have no
+// order of their own.
+var stackOrders = map[string][]OptionType{
+	"linux": {
+		RecordRoute,
+		InternetTimestamp,
+		LooseSourceRecordRoute,
+		StrictSourceRecordRoute,
+		CommercialSecurity,
+	},
+}
+
+// p0fTokens maps option types to the tokens used by P0fOptionString.
+var p0fTokens = map[OptionType]string{
+	NoOperation:             "nop",
+	Security:                "sec",
+	LooseSourceRecordRoute:  "lsrr",
+	StrictSourceRecordRoute: "ssrr",
+	RecordRoute:             "rr",
+	StreamIdentifier:        "sid",
+	InternetTimestamp:       "ts",
+	RouterAlertOption:       "ra",
+	CommercialSecurity:      "cipso",
+	ExtendedSecurity:        "esec",
+	MTUProbeOption:          "mtup",
+	MTUReplyOption:          "mtur",
+	TracerouteOption:        "tr",
+	QuickStartOption:        "qs",
+	ExperimentalOption30:    "exp",
+	ExperimentalOption94:    "exp",
+	ExperimentalOption158:   "exp",
+	ExperimentalOption222:   "exp",
+	SDBOption:               "sdb",
+	UMPOption:               "ump",
+}
+
+// P0fOptionString returns the layout of the options as a comma separated
+// signature in the style p0f uses for option layouts. Options map to tokens
+// as follows:
+//
+//	EndOfOptionList          eol+N, where N is the number of padding bytes after it
+//	NoOperation              nop
+//	Security                 sec
+//	LooseSourceRecordRoute   lsrr
+//	StrictSourceRecordRoute  ssrr
+//	RecordRoute              rr
+//	StreamIdentifier         sid
+//	InternetTimestamp        ts
+//	RouterAlertOption        ra
+//	CommercialSecurity       cipso
+//	ExtendedSecurity         esec
+//	MTUProbeOption           mtup
+//	MTUReplyOption           mtur
+//	TracerouteOption         tr
+//	QuickStartOption         qs
+//	ExperimentalOption*      exp
+//	SDBOption                sdb
+//	UMPOption                ump
+//	anything else            ?N, where N is the option type
+func (o Options) P0fOptionString() string {
+	var tokens []string
+	for i := 0; i < len(o); i++ {
+		if _, ok := o[i].(EOOList); ok {
+			var pad int
+			for _, opt := range o[i+1:] {
+				pad += opt.Length()
+			}
+			tokens = append(tokens, fmt.Sprintf("eol+%d", pad))
+			break
+		}
+		tok, ok := p0fTokens[o[i].Type()]
+		if !ok {
+			tok = fmt.Sprintf("?%d", o[i].Type())
+		}
+		tokens = append(tokens, tok)
+	}
+	return strings.Join(tokens, ",")
+}
+
+// OrderLike returns a copy of the options reordered to match the order in
+// which the named stack ("linux") emits them, see stackOrders.
+// Options the stack does not order keep their relative order after the
+// known ones, and padding options are moved to the end.
+func (o Options) OrderLike(stack string) (Options, error) {
+	order, ok := stackOrders[stack]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownStack, stack)
+	}
+	ranks := make(map[OptionType]int, len(order))
+	for i, t := range order {
+		ranks[t] = i
+	}
+	rank := func(opt IPOption) int {
+		if isPadding(opt) {
+			return len(order) + 1
+		}
+		if r, ok := ranks[opt.Type()]; ok {
+			return r
+		}
+		return len(order)
+	}
+	ordered := make(Options, len(o))
+	copy(ordered, o)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return rank(ordered[i]) < rank(ordered[j])
+	})
+	return ordered, nil
+}
@@ -0,0 +1,52 @@
+package ipv4opt_test
+
+import (
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func TestP0fOptionString(t *testing.T) {
+	data := append(append([]byte{}, secTest...), tsPreSpec...)
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	expected := "sec,ts,eol+0"
+	if ops.P0fOptionString() != expected {
+		t.Fatalf("Wrong signature, Expected(%v), Got(%v)", expected, ops.P0fOptionString())
+	}
+}
+
+func TestOrderLike(t *testing.T) {
+	var data []byte
+	data = append(data, tsPreSpec[:12]...)
+	data = append(data, sidTest...)
+	data = append(data, ipv4opt.NoOperation)
+	data = append(data, secTest...)
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	ordered, err := ops.OrderLike("linux")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []ipv4opt.OptionType{
+		ipv4opt.InternetTimestamp,
+		ipv4opt.StreamIdentifier,
+		ipv4opt.Security,
+		ipv4opt.NoOperation,
+	}
+	if len(ordered) != len(expected) {
+		t.Fatalf("Wrong number of options, Expected(%v), Got(%v)", len(expected), len(ordered))
+	}
+	for i, opt := range ordered {
+		if opt.Type() != expected[i] {
+			t.Fatalf("Wrong option type at %v, Expected(%v), Got(%v)", i, expected[i], opt.Type())
+		}
+	}
+	if _, err := ops.OrderLike("bsd"); err == nil {
+		t.Fatalf("Expected error for unknown stack")
+	}
+}
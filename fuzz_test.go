@@ -0,0 +1,138 @@
+package ipv4opt_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range [][]byte{
+		rrTest, tsTest, tsTest2, tsPreSpec, secTest, sidTest, cipsoTest,
+		{7, 0}, {68, 0}, {68, 2, 5}, {136, 2}, {131, 1}, {7, 200, 4},
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ops, err := ipv4opt.Parse(data)
+		if err == nil {
+			ops.Marshal()
+			_ = ops.String()
+			if _, err := ops.MarshalJSON(); err != nil {
+				t.Fatalf("Failed to marshal parsed options to JSON: %v", err)
+			}
+		}
+		ipv4opt.ParseLenient(data)
+		ipv4opt.ParseStrict(data)
+		ipv4opt.Lazy(data).Get(ipv4opt.Security)
+	})
+}
+
+func FuzzRecordRoute(f *testing.F) {
+	for _, seed := range [][]byte{
+		rrTest[:39], rrEmptyTest[:11], {131, 7, 4, 10, 0, 0, 1}, {137, 3, 4}, {7, 7, 0, 0, 0, 0, 0},
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var rr ipv4opt.RR
+		if err := rr.UnmarshalBinary(data); err != nil {
+			return
+		}
+		_ = rr.Recorded()
+		_ = rr.FreeSlots()
+		_ = rr.String()
+		b, err := rr.MarshalBinary()
+		if err != nil {
+			t.Fatalf("Failed to marshal parsed option: %v", err)
+		}
+		if !bytes.Equal(b, data) {
+			t.Fatalf("Expected(%v), Got(%v)", data, b)
+		}
+	})
+}
+
+func FuzzTimestamp(f *testing.F) {
+	for _, seed := range [][]byte{
+		tsTest, tsTest2, tsPreSpec[:12], {68, 4, 5, 0}, {68, 6, 5, 2, 0, 0}, {68, 8, 1, 0xf1, 0, 0, 0, 0},
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var ts ipv4opt.TS
+		if err := ts.UnmarshalBinary(data); err != nil {
+			return
+		}
+		_ = ts.Recorded()
+		_ = ts.Remaining()
+		_ = ts.String()
+		b, err := ts.MarshalBinary()
+		if err != nil {
+			t.Fatalf("Failed to marshal parsed option: %v", err)
+		}
+		var ts2 ipv4opt.TS
+		if err := ts2.UnmarshalBinary(b); err != nil {
+			t.Fatalf("Failed to parse marshaled option %v: %v", b, err)
+		}
+		if !ts.Equal(ts2) {
+			t.Fatalf("Expected(%v), Got(%v)", ts, ts2)
+		}
+	})
+}
+
+func FuzzSecurity(f *testing.F) {
+	for _, seed := range [][]byte{
+		secTest, {130, 3, 0x5a}, {130, 4, 0x3d, 0x80}, {130, 6, 0xD7, 0x88, 0, 0},
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var s ipv4opt.Sec
+		if s.UnmarshalBinary(data) == nil {
+			_ = s.String()
+			_ = s.TCCString()
+			b, err := s.MarshalBinary()
+			if err != nil {
+				t.Fatalf("Failed to marshal parsed option: %v", err)
+			}
+			if data[1] == 11 && !bytes.Equal(b, data) {
+				t.Fatalf("Expected(%v), Got(%v)", data, b)
+			}
+		}
+		var bs ipv4opt.BasicSec
+		if bs.UnmarshalBinary(data) == nil {
+			_ = bs.String()
+		}
+		var es ipv4opt.ESec
+		if es.UnmarshalBinary(data) == nil {
+			_ = es.String()
+		}
+	})
+}
+
+func FuzzCIPSO(f *testing.F) {
+	for _, seed := range [][]byte{
+		cipsoTest, {134, 6, 0, 0, 0, 1}, {134, 10, 0, 0, 0, 3, 1, 4, 0, 5},
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var c ipv4opt.CIPSO
+		if err := c.UnmarshalBinary(data); err != nil {
+			return
+		}
+		_ = c.String()
+		b, err := c.MarshalBinary()
+		if err != nil {
+			t.Fatalf("Failed to marshal parsed option: %v", err)
+		}
+		var c2 ipv4opt.CIPSO
+		if err := c2.UnmarshalBinary(b); err != nil {
+			t.Fatalf("Failed to parse marshaled option %v: %v", b, err)
+		}
+		if !c.Equal(c2) {
+			t.Fatalf("Expected(%v), Got(%v)", c, c2)
+		}
+	})
+}
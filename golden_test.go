@@ -0,0 +1,86 @@
+package ipv4opt_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+var update = flag.Bool("update", false, "rewrite the expected output of the golden tests")
+
+// goldenResult is the expected output of a golden test.
+type goldenResult struct {
+	Options ipv4opt.Options `json:"options"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// readHex decodes a golden input file: hex digits with any whitespace, and
+// comments from # to the end of the line.
+func readHex(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var digits strings.Builder
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line, _, _ := strings.Cut(s.Text(), "#")
+		digits.WriteString(strings.Join(strings.Fields(line), ""))
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(digits.String())
+}
+
+// TestGolden parses every testdata/golden/*.hex file and compares the result
+// with the JSON in the .json file of the same name. Run with -update to
+// write the .json files from the current output.
+func TestGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "golden", "*.hex"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) == 0 {
+		t.Fatalf("No golden test inputs found")
+	}
+	for _, in := range inputs {
+		data, err := readHex(in)
+		if err != nil {
+			t.Fatalf("%s: Failed to read input: %v", in, err)
+		}
+		var res goldenResult
+		res.Options, err = ipv4opt.Parse(data)
+		if err != nil {
+			res.Error = err.Error()
+		}
+		got, err := json.MarshalIndent(res, "", "\t")
+		if err != nil {
+			t.Fatalf("%s: %v", in, err)
+		}
+		got = append(got, '\n')
+		out := strings.TrimSuffix(in, ".hex") + ".json"
+		if *update {
+			if err := os.WriteFile(out, got, 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		expected, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatalf("%s: Failed to read expected output, run go test -update: %v", in, err)
+		}
+		if !bytes.Equal(got, expected) {
+			t.Fatalf("%s: Wrong output, Expected(%s), Got(%s)", in, expected, got)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package ipv4opt
+
+import "encoding/binary"
+
+const (
+	// DontFragment is the don't fragment flag of an IPv4 header.
+	DontFragment = 0x2
+	// MoreFragments is the more fragments flag of an IPv4 header.
+	MoreFragments = 0x1
+)
+
+// Header is the fixed part of an IPv4 header.
+type Header struct {
+	Version uint8
+	// IHL is the header length in 32-bit words, including options.
+	IHL         uint8
+	DSCP        uint8
+	ECN         uint8
+	TotalLength uint16
+	ID          uint16
+	Flags       uint8
+	// FragOffset is the fragment offset in units of 8 bytes.
+	FragOffset uint16
+	TTL        uint8
+	Protocol   uint8
+	Checksum   uint16
+	Src        Address
+	Dst        Address
+	// RawOptions holds the options as carried in the header. Use
+	// SetOptions to change them so the length fields and checksum are
+	// updated.
+	RawOptions []byte
+}
+
+// ParseHeader parses the IPv4 header at the start of pkt and the options
+// following its fixed part, as described by the IHL field.
+func ParseHeader(pkt []byte) (*Header, Options, error) {
+	opts, err := optionsRegion(pkt)
+	if err != nil {
+		return nil, nil, err
+	}
+	h := &Header{
+		Version:     pkt[0] >> 4,
+		IHL:         pkt[0] & 0x0f,
+		DSCP:        pkt[1] >> 2,
+		ECN:         pkt[1] & 0x03,
+		TotalLength: binary.BigEndian.Uint16(pkt[2:]),
+		ID:          binary.BigEndian.Uint16(pkt[4:]),
+		Flags:       pkt[6] >> 5,
+		FragOffset:  binary.BigEndian.Uint16(pkt[6:]) & 0x1fff,
+		TTL:         pkt[8],
+		Protocol:    pkt[9],
+		Checksum:    binary.BigEndian.Uint16(pkt[10:]),
+		Src:         Address(binary.BigEndian.Uint32(pkt[12:])),
+		Dst:         Address(binary.BigEndian.Uint32(pkt[16:])),
+	}
+	h.RawOptions = make([]byte, len(opts))
+	copy(h.RawOptions, opts)
+	options, err := Parse(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return h, options, nil
+}
+
+// Marshal returns the wire format of the header, including its options. The
+// IHL written is derived from the length of RawOptions.
+func (h *Header) Marshal() ([]byte, error) {
+	if len(h.RawOptions) > MaxOptionsLen || len(h.RawOptions)%4 != 0 {
+		return nil, ErrBadIHL
+	}
+	b := make([]byte, headerLen, headerLen+len(h.RawOptions))
+	b[0] = 4<<4 | byte((headerLen+len(h.RawOptions))/4)
+	b[1] = h.DSCP<<2 | h.ECN&0x03
+	b[2], b[3] = byte(h.TotalLength>>8), byte(h.TotalLength)
+	b[4], b[5] = byte(h.ID>>8), byte(h.ID)
+	b[6], b[7] = h.Flags<<5|byte(h.FragOffset>>8)&0x1f, byte(h.FragOffset)
+	b[8], b[9] = h.TTL, h.Protocol
+	b[10], b[11] = byte(h.Checksum>>8), byte(h.Checksum)
+	b[12], b[13], b[14], b[15] = byte(h.Src>>24), byte(h.Src>>16), byte(h.Src>>8), byte(h.Src)
+	b[16], b[17], b[18], b[19] = byte(h.Dst>>24), byte(h.Dst>>16), byte(h.Dst>>8), byte(h.Dst)
+	return append(b, h.RawOptions...), nil
+}
+
+// ComputeChecksum returns the header checksum of the header and its options,
+// computed with the checksum field set to zero. It returns the error of
+// Marshal if the header can not be marshaled.
+func (h *Header) ComputeChecksum() (uint16, error) {
+	c := *h
+	c.Checksum = 0
+	b, err := c.Marshal()
+	if err != nil {
+		return 0, err
+	}
+	return ^onesSum(b), nil
+}
+
+// SetOptions replaces the header's options with o, padded to a 32-bit
+// boundary, and updates IHL, TotalLength and Checksum to match. The header
+// is left unchanged on error.
+func (h *Header) SetOptions(o Options) error {
+	b, err := o.Marshal()
+	if err != nil {
+		return err
+	}
+	c := *h
+	c.RawOptions = b
+	c.IHL = uint8((headerLen + len(b)) / 4)
+	if c.TotalLength != 0 {
+		c.TotalLength = uint16(int(c.TotalLength) + len(b) - len(h.RawOptions))
+	}
+	if c.Checksum, err = c.ComputeChecksum(); err != nil {
+		return err
+	}
+	*h = c
+	return nil
+}
+
+// VerifyChecksum reports whether the checksum of the IPv4 header at the
+// start of raw, including its options, is correct.
+func VerifyChecksum(raw []byte) bool {
+	if len(raw) < headerLen {
+		return false
+	}
+	ihl := int(raw[0]&0x0f) * 4
+	if ihl < headerLen || ihl > len(raw) {
+		return false
+	}
+	return onesSum(raw[:ihl]) == 0xffff
+}
+
+// onesSum returns the 16-bit ones' complement sum of b.
+func onesSum(b []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(b); i += 2 {
+		sum += uint32(b[i])<<8 | uint32(b[i+1])
+	}
+	if len(b)%2 != 0 {
+		sum += uint32(b[len(b)-1]) << 8
+	}
+	for sum > 0xffff {
+		sum = sum>>16 + sum&0xffff
+	}
+	return uint16(sum)
+}
@@ -0,0 +1,99 @@
+package ipv4opt_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+var headerTest = []byte{
+	0x46, 0x00, 0x00, 0x20, 0x00, 0x01, 0x40, 0x00,
+	0x40, 0x01, 0x00, 0x00, 0xc0, 0xa8, 0x00, 0x01,
+	0xc0, 0xa8, 0x00, 0x02, 0x88, 0x04, 0x12, 0x34,
+	0x08, 0x00, 0xf7, 0xfe, 0x00, 0x01, 0x00, 0x00,
+}
+
+func TestParseHeader(t *testing.T) {
+	h, ops, err := ipv4opt.ParseHeader(headerTest)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	expected := ipv4opt.Header{
+		Version:     4,
+		IHL:         6,
+		TotalLength: 32,
+		ID:          1,
+		Flags:       ipv4opt.DontFragment,
+		TTL:         64,
+		Protocol:    1,
+		Src:         0xc0a80001,
+		Dst:         0xc0a80002,
+		RawOptions:  headerTest[20:24],
+	}
+	if !reflect.DeepEqual(*h, expected) {
+		t.Fatalf("Wrong header, Expected(%+v), Got(%+v)", expected, *h)
+	}
+	if len(ops) != 1 || ops[0].Type() != ipv4opt.StreamIdentifier {
+		t.Fatalf("Wrong options, Got(%v)", ops)
+	}
+	if _, _, err := ipv4opt.ParseHeader(headerTest[:22]); !errors.Is(err, ipv4opt.ErrShortPacket) {
+		t.Fatalf("Wrong error, Expected(%v), Got(%v)", ipv4opt.ErrShortPacket, err)
+	}
+}
+
+func TestHeaderChecksum(t *testing.T) {
+	h, _, err := ipv4opt.ParseHeader(headerTest)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	if ipv4opt.VerifyChecksum(headerTest) {
+		t.Fatalf("Expected checksum of test data to be wrong")
+	}
+	if h.Checksum, err = h.ComputeChecksum(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := h.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ipv4opt.VerifyChecksum(b) {
+		t.Fatalf("Wrong checksum %#04x for %v", h.Checksum, b)
+	}
+
+	rr, err := ipv4opt.NewRecordRoute(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.SetOptions(ipv4opt.Options{rr}); err != nil {
+		t.Fatal(err)
+	}
+	if h.IHL != 9 || h.TotalLength != 44 {
+		t.Fatalf("Wrong lengths, Expected(%v, %v), Got(%v, %v)", 9, 44, h.IHL, h.TotalLength)
+	}
+	b, err = h.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ipv4opt.VerifyChecksum(b) {
+		t.Fatalf("Wrong checksum %#04x for %v", h.Checksum, b)
+	}
+
+	bad := *h
+	bad.RawOptions = []byte{1, 1, 1}
+	if sum, err := bad.ComputeChecksum(); !errors.Is(err, ipv4opt.ErrBadIHL) || sum != 0 {
+		t.Fatalf("Wrong checksum result for unpadded options, Expected(0 %v), Got(%#04x %v)", ipv4opt.ErrBadIHL, sum, err)
+	}
+	big, err := ipv4opt.NewRecordRoute(9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := *h
+	if err := h.SetOptions(ipv4opt.Options{big, big}); err == nil {
+		t.Fatalf("Expected error for options that do not fit")
+	}
+	if !reflect.DeepEqual(*h, saved) {
+		t.Fatalf("Header changed by failed SetOptions, Expected(%+v), Got(%+v)", saved, *h)
+	}
+}
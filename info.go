@@ -0,0 +1,92 @@
+package ipv4opt
+
+import "fmt"
+
+// OptionStatus is the status of an option type in the IANA registry.
+type OptionStatus uint8
+
+const (
+	// StatusCurrent is the status of options in current use.
+	StatusCurrent OptionStatus = iota
+	// StatusDeprecated is the status of options deprecated by RFC 6814.
+	// They should not be sent and may be ignored.
+	StatusDeprecated
+	// StatusObsolete is the status of options replaced by another mechanism.
+	StatusObsolete
+)
+
+func (s OptionStatus) String() string {
+	switch s {
+	case StatusCurrent:
+		return "Current"
+	case StatusDeprecated:
+		return "Deprecated"
+	case StatusObsolete:
+		return "Obsolete"
+	}
+	return fmt.Sprintf("OptionStatus(%d)", uint8(s))
+}
+
+// OptionInfo describes an option type registered with IANA.
+type OptionInfo struct {
+	Type OptionType
+	// Name is the abbreviation the registry gives the option, such as RR.
+	Name string
+	// Description is the full name of the option, such as Record Route.
+	Description string
+	// References are the documents defining the option.
+	References []string
+	Status     OptionStatus
+}
+
+// optionInfo is the IANA "IP Option Numbers" registry. The references are
+// copied from the registry, which names individuals where no document
+// defines an option.
+var optionInfo = map[OptionType]OptionInfo{
+	0:   {0, "EOOL", "End of Options List", []string{"RFC 791", "Jon_Postel"}, StatusCurrent},
+	1:   {1, "NOP", "No Operation", []string{"RFC 791", "Jon_Postel"}, StatusCurrent},
+	7:   {7, "RR", "Record Route", []string{"RFC 791", "Jon_Postel"}, StatusCurrent},
+	10:  {10, "ZSU", "Experimental Measurement", []string{"ZSu"}, StatusCurrent},
+	11:  {11, "MTUP", "MTU Probe", []string{"RFC 1063", "RFC 1191"}, StatusObsolete},
+	12:  {12, "MTUR", "MTU Reply", []string{"RFC 1063", "RFC 1191"}, StatusObsolete},
+	15:  {15, "ENCODE", "ENCODE", []string{"VerSteeg"}, StatusCurrent},
+	25:  {25, "QS", "Quick-Start", []string{"RFC 4782"}, StatusCurrent},
+	30:  {30, "EXP", "RFC3692-style Experiment", []string{"RFC 4727"}, StatusCurrent},
+	68:  {68, "TS", "Time Stamp", []string{"RFC 791", "Jon_Postel"}, StatusCurrent},
+	82:  {82, "TR", "Traceroute", []string{"RFC 1393", "RFC 6814"}, StatusDeprecated},
+	94:  {94, "EXP", "RFC3692-style Experiment", []string{"RFC 4727"}, StatusCurrent},
+	130: {130, "SEC", "Security", []string{"RFC 1108"}, StatusCurrent},
+	131: {131, "LSR", "Loose Source Route", []string{"RFC 791", "Jon_Postel"}, StatusCurrent},
+	133: {133, "E-SEC", "Extended Security", []string{"RFC 1108"}, StatusCurrent},
+	134: {134, "CIPSO", "Commercial Security", []string{"draft-ietf-cipso-ipsecurity-01"}, StatusCurrent},
+	136: {136, "SID", "Stream ID", []string{"RFC 791", "Jon_Postel", "RFC 6814"}, StatusDeprecated},
+	137: {137, "SSR", "Strict Source Route", []string{"RFC 791", "Jon_Postel"}, StatusCurrent},
+	142: {142, "VISA", "Experimental Access Control", []string{"Deborah_Estrin"}, StatusCurrent},
+	144: {144, "IMITD", "IMI Traffic Descriptor", []string{"Lee"}, StatusCurrent},
+	145: {145, "EIP", "Extended Internet Protocol", []string{"RFC 1385", "RFC 6814"}, StatusDeprecated},
+	147: {147, "ADDEXT", "Address Extension", []string{"Ullmann IPv7", "RFC 6814"}, StatusDeprecated},
+	148: {148, "RTRALT", "Router Alert", []string{"RFC 2113"}, StatusCurrent},
+	149: {149, "SDB", "Selective Directed Broadcast", []string{"Charles_Bud_Graff", "RFC 6814"}, StatusDeprecated},
+	151: {151, "DPS", "Dynamic Packet State", []string{"Andy_Malis", "RFC 6814"}, StatusDeprecated},
+	152: {152, "UMP", "Upstream Multicast Pkt.", []string{"Dino_Farinacci", "RFC 6814"}, StatusDeprecated},
+	158: {158, "EXP", "RFC3692-style Experiment", []string{"RFC 4727"}, StatusCurrent},
+	205: {205, "FINN", "Experimental Flow Control", []string{"Greg_Finn"}, StatusCurrent},
+	222: {222, "EXP", "RFC3692-style Experiment", []string{"RFC 4727"}, StatusCurrent},
+}
+
+// LookupOptionInfo returns the registry entry for option type t, including
+// types this package does not decode. It reports false for unassigned
+// types.
+func LookupOptionInfo(t OptionType) (OptionInfo, bool) {
+	info, ok := optionInfo[t]
+	if !ok {
+		return OptionInfo{}, false
+	}
+	info.References = append([]string(nil), info.References...)
+	return info, true
+}
+
+// Info returns the registry entry for the option type, see LookupOptionInfo.
+func (t OptionType) Info() (OptionInfo, bool) {
+	return LookupOptionInfo(t)
+}
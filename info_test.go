@@ -0,0 +1,64 @@
+package ipv4opt_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func TestLookupOptionInfo(t *testing.T) {
+	tests := []struct {
+		otype  ipv4opt.OptionType
+		name   string
+		status ipv4opt.OptionStatus
+		ok     bool
+	}{
+		{ipv4opt.RecordRoute, "RR", ipv4opt.StatusCurrent, true},
+		{ipv4opt.StreamIdentifier, "SID", ipv4opt.StatusDeprecated, true},
+		{ipv4opt.MTUProbeOption, "MTUP", ipv4opt.StatusObsolete, true},
+		{ipv4opt.ExperimentalOption158, "EXP", ipv4opt.StatusCurrent, true},
+		{151, "DPS", ipv4opt.StatusDeprecated, true},
+		{31, "", ipv4opt.StatusCurrent, false},
+	}
+	for _, test := range tests {
+		info, ok := ipv4opt.LookupOptionInfo(test.otype)
+		if ok != test.ok {
+			t.Fatalf("Wrong lookup result for %v, Expected(%v), Got(%v)", test.otype, test.ok, ok)
+		}
+		if info.Name != test.name || info.Status != test.status {
+			t.Fatalf("Wrong info for %v, Expected(%v %v), Got(%v %v)", test.otype, test.name, test.status, info.Name, info.Status)
+		}
+		if ok && (info.Type != test.otype || len(info.References) == 0) {
+			t.Fatalf("Incomplete info for %v, Got(%+v)", test.otype, info)
+		}
+	}
+	info, _ := ipv4opt.LookupOptionInfo(ipv4opt.RecordRoute)
+	info.References[0] = "changed"
+	if info, _ := ipv4opt.OptionType(ipv4opt.RecordRoute).Info(); info.References[0] != "RFC 791" {
+		t.Fatalf("Registry was modified through a lookup, Got(%v)", info.References)
+	}
+}
+
+func TestOptionInfoMatchesRegistry(t *testing.T) {
+	tests := []struct {
+		otype  ipv4opt.OptionType
+		refs   []string
+		status ipv4opt.OptionStatus
+	}{
+		{10, []string{"ZSu"}, ipv4opt.StatusCurrent},
+		{15, []string{"VerSteeg"}, ipv4opt.StatusCurrent},
+		{ipv4opt.TracerouteOption, []string{"RFC 1393", "RFC 6814"}, ipv4opt.StatusDeprecated},
+		{ipv4opt.StreamIdentifier, []string{"RFC 791", "Jon_Postel", "RFC 6814"}, ipv4opt.StatusDeprecated},
+		{142, []string{"Deborah_Estrin"}, ipv4opt.StatusCurrent},
+		{144, []string{"Lee"}, ipv4opt.StatusCurrent},
+		{ipv4opt.SDBOption, []string{"Charles_Bud_Graff", "RFC 6814"}, ipv4opt.StatusDeprecated},
+		{205, []string{"Greg_Finn"}, ipv4opt.StatusCurrent},
+	}
+	for _, test := range tests {
+		info, ok := ipv4opt.LookupOptionInfo(test.otype)
+		if !ok || !reflect.DeepEqual(info.References, test.refs) || info.Status != test.status {
+			t.Fatalf("Wrong info for %v, Expected(%v %v), Got(%v %v)", test.otype, test.refs, test.status, info.References, info.Status)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+// Package ipv4optexpvar publishes the counters of an ipv4opt.Stats through
+// the expvar package, so that they are served at /debug/vars.
+package ipv4optexpvar
+
+import (
+	"expvar"
+
+	ipv4opt "github.com/rhansen2/ipv4optparser"
+)
+
+// Publish publishes the counters of s under name. The value is computed from
+// a fresh snapshot every time it is read. Like expvar.Publish, it panics if
+// name is already in use.
+func Publish(name string, s *ipv4opt.Stats) {
+	expvar.Publish(name, Var(s))
+}
+
+// Var returns an expvar.Var reporting the counters of s as a JSON object
+// with the fields of ipv4opt.StatsSnapshot. Option types are keyed by name.
+func Var(s *ipv4opt.Stats) expvar.Var {
+	return expvar.Func(func() any {
+		return s.Snapshot()
+	})
+}
@@ -0,0 +1,34 @@
+package ipv4optexpvar_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+
+	ipv4opt "github.com/rhansen2/ipv4optparser"
+	"github.com/rhansen2/ipv4optparser/ipv4optexpvar"
+)
+
+func TestPublish(t *testing.T) {
+	var stats ipv4opt.Stats
+	p := ipv4opt.NewParser(ipv4opt.WithStats(&stats))
+	if _, err := p.Parse([]byte{148, 4, 0, 0, 1, 1}); err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	ipv4optexpvar.Publish("ipv4opt", &stats)
+	v := expvar.Get("ipv4opt")
+	if v == nil {
+		t.Fatal("Stats were not published")
+	}
+	var got struct {
+		Buffers uint64
+		Bytes   uint64
+		Options map[string]uint64
+	}
+	if err := json.Unmarshal([]byte(v.String()), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Buffers != 1 || got.Bytes != 6 || got.Options["RTRALT"] != 1 || got.Options["NOP"] != 2 {
+		t.Fatalf("Wrong published stats, Got(%+v)", got)
+	}
+}
@@ -0,0 +1,88 @@
+// Package ipv4optlayer exposes the IPv4 options area as a gopacket layer so
+// that ipv4opt can be used from gopacket decoding pipelines.
+package ipv4optlayer
+
+import (
+	"errors"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	ipv4opt "github.com/rhansen2/ipv4optparser"
+)
+
+// LayerTypeIPv4Options is the layer type of the IPv4 options area.
+var LayerTypeIPv4Options = gopacket.RegisterLayerType(1791, gopacket.LayerTypeMetadata{
+	Name:    "IPv4Options",
+	Decoder: gopacket.DecodeFunc(decodeIPv4Options),
+})
+
+// IPv4Options is the options area of an IPv4 header. It implements
+// gopacket.DecodingLayer and gopacket.SerializableLayer.
+type IPv4Options struct {
+	layers.BaseLayer
+	Options ipv4opt.Options
+}
+
+var (
+	_ gopacket.DecodingLayer     = (*IPv4Options)(nil)
+	_ gopacket.SerializableLayer = (*IPv4Options)(nil)
+)
+
+// LayerType returns LayerTypeIPv4Options.
+func (o *IPv4Options) LayerType() gopacket.LayerType { return LayerTypeIPv4Options }
+
+// CanDecode returns LayerTypeIPv4Options.
+func (o *IPv4Options) CanDecode() gopacket.LayerClass { return LayerTypeIPv4Options }
+
+// NextLayerType returns gopacket.LayerTypeZero; the options area has no
+// payload of its own.
+func (o *IPv4Options) NextLayerType() gopacket.LayerType { return gopacket.LayerTypeZero }
+
+// DecodeFromBytes parses data as an IPv4 options area, reusing the Options
+// slice from any previous decode. The packet is marked truncated only when
+// an option runs past the end of data.
+func (o *IPv4Options) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	opts, err := ipv4opt.ParseInto(data, o.Options[:0])
+	if err != nil {
+		if errors.Is(err, ipv4opt.ErrTruncatedOption) || errors.Is(err, ipv4opt.ErrShortPacket) {
+			df.SetTruncated()
+		}
+		return err
+	}
+	o.Options = opts
+	o.Contents = data
+	o.Payload = nil
+	return nil
+}
+
+// DecodeFromIPv4 decodes the options area of an already decoded IPv4 layer.
+func (o *IPv4Options) DecodeFromIPv4(ip *layers.IPv4) error {
+	end := int(ip.IHL) * 4
+	if end < 20 || end > len(ip.Contents) {
+		return ipv4opt.ErrBadIHL
+	}
+	return o.DecodeFromBytes(ip.Contents[20:end], gopacket.NilDecodeFeedback)
+}
+
+// SerializeTo writes the options, padded to a 32-bit boundary, to b.
+func (o *IPv4Options) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	raw, err := o.Options.Marshal()
+	if err != nil {
+		return err
+	}
+	bytes, err := b.PrependBytes(len(raw))
+	if err != nil {
+		return err
+	}
+	copy(bytes, raw)
+	return nil
+}
+
+func decodeIPv4Options(data []byte, p gopacket.PacketBuilder) error {
+	o := &IPv4Options{}
+	if err := o.DecodeFromBytes(data, p); err != nil {
+		return err
+	}
+	p.AddLayer(o)
+	return nil
+}
@@ -0,0 +1,72 @@
+package ipv4optlayer_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	ipv4opt "github.com/rhansen2/ipv4optparser"
+	"github.com/rhansen2/ipv4optparser/ipv4optlayer"
+)
+
+var packetTest = []byte{
+	0x46, 0x00, 0x00, 0x18, 0x00, 0x01, 0x00, 0x00,
+	0x40, 0x11, 0x00, 0x00, 0xc0, 0xa8, 0x00, 0x01,
+	0xc0, 0xa8, 0x00, 0x02, 0x94, 0x04, 0x00, 0x00,
+}
+
+func TestDecodeFromIPv4(t *testing.T) {
+	var ip layers.IPv4
+	if err := ip.DecodeFromBytes(packetTest, gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	var o ipv4optlayer.IPv4Options
+	if err := o.DecodeFromIPv4(&ip); err != nil {
+		t.Fatal(err)
+	}
+	if len(o.Options) != 1 || o.Options[0].Type() != ipv4opt.RouterAlertOption {
+		t.Fatalf("Expected a router alert option, Got(%v)", o.Options)
+	}
+}
+
+func TestSerializeTo(t *testing.T) {
+	var o ipv4optlayer.IPv4Options
+	if err := o.DecodeFromBytes(packetTest[20:], gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	buf := gopacket.NewSerializeBuffer()
+	if err := o.SerializeTo(buf, gopacket.SerializeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), packetTest[20:]) {
+		t.Fatalf("Expected(%v), Got(%v)", packetTest[20:], buf.Bytes())
+	}
+}
+
+// truncatedFeedback records whether a decode marked the packet truncated.
+type truncatedFeedback bool
+
+func (f *truncatedFeedback) SetTruncated() { *f = true }
+
+func TestDecodeFromBytesErrors(t *testing.T) {
+	for _, test := range []struct {
+		data      []byte
+		err       error
+		truncated bool
+	}{
+		{[]byte{7, 11, 4, 0, 0, 0, 0}, ipv4opt.ErrTruncatedOption, true},
+		{[]byte{7, 6, 4, 0, 0, 0}, ipv4opt.ErrIncorrectRRLength, false},
+	} {
+		var o ipv4optlayer.IPv4Options
+		var df truncatedFeedback
+		err := o.DecodeFromBytes(test.data, &df)
+		if !errors.Is(err, test.err) {
+			t.Fatalf("Wrong error, Expected(%v), Got(%v)", test.err, err)
+		}
+		if bool(df) != test.truncated {
+			t.Fatalf("Wrong truncation for %v, Expected(%v), Got(%v)", test.err, test.truncated, bool(df))
+		}
+	}
+}
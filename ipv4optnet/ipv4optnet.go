@@ -0,0 +1,31 @@
+// Package ipv4optnet converts between ipv4opt.Options and the headers used by
+// golang.org/x/net/ipv4, such as those passed to RawConn.WriteTo and returned
+// by RawConn.ReadFrom.
+package ipv4optnet
+
+import (
+	ipv4opt "github.com/rhansen2/ipv4optparser"
+	"golang.org/x/net/ipv4"
+)
+
+// AppendToHeader marshals opts into the options of h, replacing any options
+// already present. Len is updated to the new header length and, if TotalLen
+// is set, it is adjusted by the change in header length.
+func AppendToHeader(h *ipv4.Header, opts ipv4opt.Options) error {
+	b, err := opts.Marshal()
+	if err != nil {
+		return err
+	}
+	delta := len(b) - len(h.Options)
+	h.Options = b
+	h.Len = ipv4.HeaderLen + len(b)
+	if h.TotalLen != 0 {
+		h.TotalLen += delta
+	}
+	return nil
+}
+
+// FromHeader parses the options carried in h.
+func FromHeader(h *ipv4.Header) (ipv4opt.Options, error) {
+	return ipv4opt.Parse(h.Options)
+}
@@ -0,0 +1,46 @@
+package ipv4optnet_test
+
+import (
+	"net"
+	"testing"
+
+	ipv4opt "github.com/rhansen2/ipv4optparser"
+	"github.com/rhansen2/ipv4optparser/ipv4optnet"
+	"golang.org/x/net/ipv4"
+)
+
+func TestAppendToHeader(t *testing.T) {
+	h := &ipv4.Header{
+		Version:  ipv4.Version,
+		Len:      ipv4.HeaderLen,
+		TotalLen: ipv4.HeaderLen + 8,
+		TTL:      64,
+		Protocol: 1,
+		Dst:      net.IPv4(192, 168, 0, 1),
+	}
+	ts, err := ipv4opt.NewTimestampAddr(nil, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ipv4optnet.AppendToHeader(h, ipv4opt.Options{ts}); err != nil {
+		t.Fatal(err)
+	}
+	if h.Len != 56 || h.TotalLen != 64 {
+		t.Fatalf("Wrong lengths, Expected(%v, %v), Got(%v, %v)", 56, 64, h.Len, h.TotalLen)
+	}
+	b, err := h.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ipv4.ParseHeader(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, err := ipv4optnet.FromHeader(parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opts.Timestamps()) != 1 {
+		t.Fatalf("Expected a timestamp option, Got(%v)", opts)
+	}
+}
@@ -0,0 +1,80 @@
+package ipv4opt
+
+import (
+	"io"
+	"iter"
+)
+
+// Iterator parses options one at a time, so that callers can stop early
+// without parsing or allocating the whole list.
+type Iterator struct {
+	p      *Parser
+	opts   []byte
+	offset int
+	limits limits
+	err    error
+}
+
+// NewIterator returns an iterator over the options in opts, parsed like
+// Parse.
+func NewIterator(opts []byte) *Iterator {
+	return defaultParser.Iterator(opts)
+}
+
+// Iterator returns an iterator over the options in opts, parsed with p.
+func (p *Parser) Iterator(opts []byte) *Iterator {
+	it := &Iterator{p: p, opts: opts}
+	if len(opts) > MaxOptionsLen {
+		it.err = ErrOptionDataTooLarge
+	}
+	return it
+}
+
+// Next returns the next option. It returns io.EOF once all options have been
+// returned. After an error, Next keeps returning that error.
+func (it *Iterator) Next() (IPOption, error) {
+	for it.err == nil {
+		if it.offset >= len(it.opts) {
+			it.err = io.EOF
+			break
+		}
+		o, known, err := it.p.parseAt(it.opts, it.offset)
+		if err != nil {
+			it.err = err
+			break
+		}
+		it.offset += o.Length()
+		if !known && it.p.unknown == UnknownSkip {
+			continue
+		}
+		if err := it.limits.add(it.p, o, it.offset-o.Length()); err != nil {
+			it.err = err
+			break
+		}
+		if it.opts[it.offset-o.Length()] == EndOfOptionList {
+			it.err = it.p.checkPadding(it.opts, it.offset)
+			if it.err != nil {
+				return nil, it.err
+			}
+			it.offset = len(it.opts)
+		}
+		return o, nil
+	}
+	return nil, it.err
+}
+
+// All returns the remaining options as a sequence for use with range. A
+// parse error is yielded with a nil option and ends the sequence.
+func (it *Iterator) All() iter.Seq2[IPOption, error] {
+	return func(yield func(IPOption, error) bool) {
+		for {
+			o, err := it.Next()
+			if err == io.EOF {
+				return
+			}
+			if !yield(o, err) || err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package ipv4opt_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func TestIterator(t *testing.T) {
+	var data []byte
+	data = append(data, ipv4opt.NoOperation)
+	data = append(data, sidTest...)
+	data = append(data, 148, 4, 0, 0)
+	data = append(data, rrEmptyTest[:11]...)
+	expected, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	it := ipv4opt.NewIterator(data)
+	for i := 0; ; i++ {
+		opt, err := it.Next()
+		if err == io.EOF {
+			if i != len(expected) {
+				t.Fatalf("Wrong number of options, Expected(%v), Got(%v)", len(expected), i)
+			}
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if opt.Type() != expected[i].Type() {
+			t.Fatalf("Expected(%v), Got(%v)", expected[i].Type(), opt.Type())
+		}
+	}
+	if _, err := it.Next(); err != io.EOF {
+		t.Fatalf("Expected(%v), Got(%v)", io.EOF, err)
+	}
+
+	var seen []ipv4opt.OptionType
+	for opt, err := range ipv4opt.NewIterator(data).All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen = append(seen, opt.Type())
+		if opt.Type() == ipv4opt.RouterAlertOption {
+			break
+		}
+	}
+	if len(seen) != 3 {
+		t.Fatalf("Expected to stop at router alert, Got(%v)", seen)
+	}
+}
+
+func TestIteratorErrors(t *testing.T) {
+	tests := []struct {
+		parser *ipv4opt.Parser
+		data   []byte
+		err    error
+	}{
+		{ipv4opt.NewParser(), []byte{1, 7, 11, 4}, ipv4opt.ErrTruncatedOption},
+		{ipv4opt.NewParser(), make([]byte, 41), ipv4opt.ErrOptionDataTooLarge},
+		{ipv4opt.NewParser(ipv4opt.WithMaxNOPRun(2)), []byte{1, 1, 1, 1}, ipv4opt.ErrTooManyOptions},
+	}
+	for _, test := range tests {
+		var got error
+		for _, err := range test.parser.Iterator(test.data).All() {
+			got = err
+		}
+		if !errors.Is(got, test.err) {
+			t.Fatalf("Expected(%v), Got(%v)", test.err, got)
+		}
+	}
+}
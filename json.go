@@ -0,0 +1,731 @@
+package ipv4opt
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"time"
+)
+
+// optionNames are the IANA names of the option types, used for the Type of
+// options in JSON. The experimental types all share the IANA name EXP, so
+// their numbers are appended to keep the names unique.
+var optionNames = map[OptionType]string{
+	EndOfOptionList:         "EOOL",
+	NoOperation:             "NOP",
+	Security:                "SEC",
+	LooseSourceRecordRoute:  "LSR",
+	StrictSourceRecordRoute: "SSR",
+	RecordRoute:             "RR",
+	StreamIdentifier:        "SID",
+	InternetTimestamp:       "TS",
+	RouterAlertOption:       "RTRALT",
+	CommercialSecurity:      "CIPSO",
+	ExtendedSecurity:        "E-SEC",
+	MTUProbeOption:          "MTUP",
+	MTUReplyOption:          "MTUR",
+	TracerouteOption:        "TR",
+	QuickStartOption:        "QS",
+	ExperimentalOption30:    "EXP30",
+	ExperimentalOption94:    "EXP94",
+	ExperimentalOption158:   "EXP158",
+	ExperimentalOption222:   "EXP222",
+	// Historical options.
+	ZSUOption:              "ZSU",
+	EncodeOption:           "ENCODE",
+	VISAOption:             "VISA",
+	IMITDOption:            "IMITD",
+	EIPOption:              "EIP",
+	AddressExtensionOption: "ADDEXT",
+	SDBOption:              "SDB",
+	DPSOption:              "DPS",
+	UMPOption:              "UMP",
+	FINNOption:             "FINN",
+}
+
+// ErrBadAddress is returned when text can not be decoded as an IPv4 address.
+var ErrBadAddress = fmt.Errorf("Invalid IPv4 address")
+
+// MarshalText returns the IANA name of the option type, or its number for
+// types without one.
+func (t OptionType) MarshalText() ([]byte, error) {
+	return []byte(optionName(t)), nil
+}
+
+// optionName returns the IANA name of t, or its number for types without
+// one.
+func optionName(t OptionType) string {
+	if name, ok := optionNames[t]; ok {
+		return name
+	}
+	return strconv.Itoa(int(t))
+}
+
+// UnmarshalText sets the option type from its IANA name or its number.
+func (t *OptionType) UnmarshalText(text []byte) error {
+	for ot, name := range optionNames {
+		if name == string(text) {
+			*t = ot
+			return nil
+		}
+	}
+	n, err := strconv.ParseUint(string(text), 10, 8)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrOptionType, text)
+	}
+	*t = OptionType(n)
+	return nil
+}
+
+// MarshalText returns the address in dotted-quad form.
+func (addr Address) MarshalText() ([]byte, error) {
+	return []byte(addr.String()), nil
+}
+
+// UnmarshalText sets the address from its dotted-quad form.
+func (addr *Address) UnmarshalText(text []byte) error {
+	a, err := netip.ParseAddr(string(text))
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrBadAddress, text)
+	}
+	*addr, err = AddressFromAddr(a)
+	return err
+}
+
+// MarshalText returns the route in dotted-quad form.
+func (r Route) MarshalText() ([]byte, error) {
+	return Address(r).MarshalText()
+}
+
+// UnmarshalText sets the route from its dotted-quad form.
+func (r *Route) UnmarshalText(text []byte) error {
+	return (*Address)(r).UnmarshalText(text)
+}
+
+// MarshalJSON encodes the stamp with its time decoded as a UTC time of day
+// in Clock when it is a standard timestamp.
+func (s Stamp) MarshalJSON() ([]byte, error) {
+	v := struct {
+		Time  Timestamp
+		Clock string `json:",omitempty"`
+		Addr  Address
+	}{Time: s.Time, Addr: s.Addr}
+	if s.Time < msPerDay {
+		v.Clock = time.Time{}.Add(s.Time.Duration()).Format("15:04:05.000")
+	}
+	return json.Marshal(v)
+}
+
+// marshalOptionJSON encodes an option as a JSON object holding its type and
+// length followed by the exported fields of fields.
+func marshalOptionJSON(t OptionType, length int, fields interface{}) ([]byte, error) {
+	head, err := json.Marshal(struct {
+		Type   OptionType
+		Length int
+	}{t, length})
+	if err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		return head, nil
+	}
+	body, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) <= 2 {
+		return head, nil
+	}
+	head[len(head)-1] = ','
+	return append(head, body[1:]...), nil
+}
+
+// unmarshalOptionJSON decodes the fields of an option from b into fields and
+// returns the type named in b.
+func unmarshalOptionJSON(b []byte, fields interface{}) (OptionType, error) {
+	var head struct {
+		Type OptionType
+	}
+	if err := json.Unmarshal(b, &head); err != nil {
+		return 0, err
+	}
+	return head.Type, json.Unmarshal(b, fields)
+}
+
+// rebuild marshals opt from its fields and parses the result with parse, so
+// that the length and data of the returned option agree with its fields.
+func rebuild(opt marshaler, parse parseFunc) (IPOption, error) {
+	b, err := opt.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return parse(b)
+}
+
+// MarshalJSON encodes the options as a JSON array of objects. Options
+// without their own JSON encoding are encoded like a RawOption.
+func (o Options) MarshalJSON() ([]byte, error) {
+	out := make([]json.RawMessage, 0, len(o))
+	for _, opt := range o {
+		var b []byte
+		var err error
+		if m, ok := opt.(json.Marshaler); ok {
+			b, err = m.MarshalJSON()
+		} else {
+			b, err = marshalOptionJSON(opt.Type(), opt.Length(), struct{ Value []byte }{opt.Payload()})
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes options encoded by MarshalJSON. Security options
+// carrying a Classification are decoded as BasicSec and options of types
+// without a decoder as RawOption.
+func (o *Options) UnmarshalJSON(b []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	opts := make(Options, 0, len(raw))
+	for _, m := range raw {
+		opt, err := unmarshalOption(m)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, opt)
+	}
+	*o = opts
+	return nil
+}
+
+func unmarshalOption(b []byte) (IPOption, error) {
+	var head struct {
+		Type           OptionType
+		Classification *Classification
+	}
+	if err := json.Unmarshal(b, &head); err != nil {
+		return nil, err
+	}
+	var opt IPOption
+	var err error
+	switch head.Type {
+	case EndOfOptionList:
+		var v EOOList
+		err = json.Unmarshal(b, &v)
+		opt = v
+	case NoOperation:
+		var v NoOp
+		err = json.Unmarshal(b, &v)
+		opt = v
+	case Security:
+		if head.Classification != nil {
+			var v BasicSec
+			err = json.Unmarshal(b, &v)
+			opt = v
+			break
+		}
+		var v Sec
+		err = json.Unmarshal(b, &v)
+		opt = v
+	case LooseSourceRecordRoute:
+		var v LSRR
+		err = json.Unmarshal(b, &v)
+		opt = v
+	case StrictSourceRecordRoute:
+		var v SSRR
+		err = json.Unmarshal(b, &v)
+		opt = v
+	case SDBOption:
+		var v SDB
+		err = json.Unmarshal(b, &v)
+		opt = v
+	case UMPOption:
+		var v UMP
+		err = json.Unmarshal(b, &v)
+		opt = v
+	case RecordRoute:
+		var v RR
+		err = json.Unmarshal(b, &v)
+		opt = v
+	case StreamIdentifier:
+		var v StreamID
+		err = json.Unmarshal(b, &v)
+		opt = v
+	case InternetTimestamp:
+		var v TS
+		err = json.Unmarshal(b, &v)
+		opt = v
+	case RouterAlertOption:
+		var v RouterAlert
+		err = json.Unmarshal(b, &v)
+		opt = v
+	case CommercialSecurity:
+		var v CIPSO
+		err = json.Unmarshal(b, &v)
+		opt = v
+	case ExtendedSecurity:
+		var v ESec
+		err = json.Unmarshal(b, &v)
+		opt = v
+	case MTUProbeOption:
+		var v MTUProbe
+		err = json.Unmarshal(b, &v)
+		opt = v
+	case MTUReplyOption:
+		var v MTUReply
+		err = json.Unmarshal(b, &v)
+		opt = v
+	case TracerouteOption:
+		var v Traceroute
+		err = json.Unmarshal(b, &v)
+		opt = v
+	case QuickStartOption:
+		var v QuickStart
+		err = json.Unmarshal(b, &v)
+		opt = v
+	case ExperimentalOption30, ExperimentalOption94, ExperimentalOption158, ExperimentalOption222:
+		var v Experimental
+		err = json.Unmarshal(b, &v)
+		opt = v
+	default:
+		var v RawOption
+		err = json.Unmarshal(b, &v)
+		opt = v
+	}
+	if err != nil {
+		return nil, err
+	}
+	return opt, nil
+}
+
+// MarshalJSON encodes the option as a JSON object.
+func (opt EOOList) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(EndOfOptionList, 1, nil)
+}
+
+// UnmarshalJSON decodes the option from a JSON object.
+func (opt *EOOList) UnmarshalJSON(b []byte) error {
+	o, err := rebuild(EOOList{}, parseEOOList)
+	if err != nil {
+		return err
+	}
+	*opt = o.(EOOList)
+	return nil
+}
+
+// MarshalJSON encodes the option as a JSON object.
+func (opt NoOp) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(NoOperation, 1, nil)
+}
+
+// UnmarshalJSON decodes the option from a JSON object.
+func (opt *NoOp) UnmarshalJSON(b []byte) error {
+	o, err := rebuild(NoOp{}, parseNOOP)
+	if err != nil {
+		return err
+	}
+	*opt = o.(NoOp)
+	return nil
+}
+
+// MarshalJSON encodes the option as a JSON object.
+func (s Sec) MarshalJSON() ([]byte, error) {
+	type plain Sec
+	return marshalOptionJSON(Security, s.Length(), plain(s))
+}
+
+// UnmarshalJSON decodes the option from a JSON object.
+func (s *Sec) UnmarshalJSON(b []byte) error {
+	type plain Sec
+	var v plain
+	if _, err := unmarshalOptionJSON(b, &v); err != nil {
+		return err
+	}
+	o, err := rebuild(Sec(v), parseSecurity)
+	if err != nil {
+		return err
+	}
+	*s = o.(Sec)
+	return nil
+}
+
+// MarshalJSON encodes the option as a JSON object.
+func (bs BasicSec) MarshalJSON() ([]byte, error) {
+	type plain BasicSec
+	return marshalOptionJSON(Security, bs.Length(), plain(bs))
+}
+
+// UnmarshalJSON decodes the option from a JSON object.
+func (bs *BasicSec) UnmarshalJSON(b []byte) error {
+	type plain BasicSec
+	var v plain
+	if _, err := unmarshalOptionJSON(b, &v); err != nil {
+		return err
+	}
+	o, err := rebuild(BasicSec(v), parseBasicSecurity)
+	if err != nil {
+		return err
+	}
+	*bs = o.(BasicSec)
+	return nil
+}
+
+// MarshalJSON encodes the option as a JSON object.
+func (rr RR) MarshalJSON() ([]byte, error) {
+	type plain RR
+	t := rr.Type()
+	if t == 0 {
+		t = RecordRoute
+	}
+	return marshalOptionJSON(t, rr.Length(), plain(rr))
+}
+
+// UnmarshalJSON decodes the option from a JSON object. The type must be one
+// of the route options.
+func (rr *RR) UnmarshalJSON(b []byte) error {
+	type plain RR
+	var v plain
+	t, err := unmarshalOptionJSON(b, &v)
+	if err != nil {
+		return err
+	}
+	switch t {
+	case 0, LooseSourceRecordRoute, StrictSourceRecordRoute, RecordRoute:
+	default:
+		return fmt.Errorf("%w: %v is not a route option", ErrOptionType, t)
+	}
+	v.option.otype = t
+	o, err := rebuild(RR(v), parseRecordRoute)
+	if err != nil {
+		return err
+	}
+	*rr = o.(RR)
+	return nil
+}
+
+// UnmarshalJSON decodes the option from a JSON object. The type must be
+// LSR.
+func (l *LSRR) UnmarshalJSON(b []byte) error {
+	var rr RR
+	if err := rr.UnmarshalJSON(b); err != nil {
+		return err
+	}
+	if rr.Type() != LooseSourceRecordRoute {
+		return fmt.Errorf("%w: %v is not a loose source route", ErrOptionType, rr.Type())
+	}
+	l.RR = rr
+	return nil
+}
+
+// UnmarshalJSON decodes the option from a JSON object. The type must be
+// SSR.
+func (s *SSRR) UnmarshalJSON(b []byte) error {
+	var rr RR
+	if err := rr.UnmarshalJSON(b); err != nil {
+		return err
+	}
+	if rr.Type() != StrictSourceRecordRoute {
+		return fmt.Errorf("%w: %v is not a strict source route", ErrOptionType, rr.Type())
+	}
+	s.RR = rr
+	return nil
+}
+
+// MarshalJSON encodes the option as a JSON object.
+func (s SDB) MarshalJSON() ([]byte, error) {
+	type plain SDB
+	return marshalOptionJSON(SDBOption, s.Length(), plain(s))
+}
+
+// UnmarshalJSON decodes the option from a JSON object. The type must be
+// SDB.
+func (s *SDB) UnmarshalJSON(b []byte) error {
+	type plain SDB
+	var v plain
+	t, err := unmarshalOptionJSON(b, &v)
+	if err != nil {
+		return err
+	}
+	if t != SDBOption {
+		return fmt.Errorf("%w: %v is not a selective directed broadcast", ErrOptionType, t)
+	}
+	v.option.otype = t
+	o, err := rebuild(SDB(v), parseSDB)
+	if err != nil {
+		return err
+	}
+	*s = o.(SDB)
+	return nil
+}
+
+// MarshalJSON encodes the option as a JSON object.
+func (u UMP) MarshalJSON() ([]byte, error) {
+	type plain UMP
+	return marshalOptionJSON(UMPOption, u.Length(), plain(u))
+}
+
+// UnmarshalJSON decodes the option from a JSON object. The type must be
+// UMP.
+func (u *UMP) UnmarshalJSON(b []byte) error {
+	type plain UMP
+	var v plain
+	t, err := unmarshalOptionJSON(b, &v)
+	if err != nil {
+		return err
+	}
+	if t != UMPOption {
+		return fmt.Errorf("%w: %v is not an upstream multicast packet option", ErrOptionType, t)
+	}
+	v.option.otype = t
+	o, err := rebuild(UMP(v), parseUMP)
+	if err != nil {
+		return err
+	}
+	*u = o.(UMP)
+	return nil
+}
+
+// MarshalJSON encodes the option as a JSON object.
+func (s StreamID) MarshalJSON() ([]byte, error) {
+	type plain StreamID
+	return marshalOptionJSON(StreamIdentifier, s.Length(), plain(s))
+}
+
+// UnmarshalJSON decodes the option from a JSON object. Only the ID is used;
+// the option is rebuilt without padding.
+func (s *StreamID) UnmarshalJSON(b []byte) error {
+	type plain StreamID
+	var v plain
+	if _, err := unmarshalOptionJSON(b, &v); err != nil {
+		return err
+	}
+	o, err := rebuild(StreamID(v), parseStreamID)
+	if err != nil {
+		return err
+	}
+	*s = o.(StreamID)
+	return nil
+}
+
+// MarshalJSON encodes the option as a JSON object.
+func (ts TS) MarshalJSON() ([]byte, error) {
+	type plain TS
+	return marshalOptionJSON(InternetTimestamp, ts.Length(), plain(ts))
+}
+
+// UnmarshalJSON decodes the option from a JSON object.
+func (ts *TS) UnmarshalJSON(b []byte) error {
+	type plain TS
+	var v plain
+	if _, err := unmarshalOptionJSON(b, &v); err != nil {
+		return err
+	}
+	o, err := rebuild(TS(v), parseTimeStamp)
+	if err != nil {
+		return err
+	}
+	*ts = o.(TS)
+	return nil
+}
+
+// MarshalJSON encodes the option as a JSON object.
+func (ra RouterAlert) MarshalJSON() ([]byte, error) {
+	type plain RouterAlert
+	return marshalOptionJSON(RouterAlertOption, ra.Length(), plain(ra))
+}
+
+// UnmarshalJSON decodes the option from a JSON object.
+func (ra *RouterAlert) UnmarshalJSON(b []byte) error {
+	type plain RouterAlert
+	var v plain
+	if _, err := unmarshalOptionJSON(b, &v); err != nil {
+		return err
+	}
+	o, err := rebuild(RouterAlert(v), parseRouterAlert)
+	if err != nil {
+		return err
+	}
+	*ra = o.(RouterAlert)
+	return nil
+}
+
+// MarshalJSON encodes the option as a JSON object.
+func (c CIPSO) MarshalJSON() ([]byte, error) {
+	type plain CIPSO
+	return marshalOptionJSON(CommercialSecurity, c.Length(), plain(c))
+}
+
+// UnmarshalJSON decodes the option from a JSON object.
+func (c *CIPSO) UnmarshalJSON(b []byte) error {
+	type plain CIPSO
+	var v plain
+	if _, err := unmarshalOptionJSON(b, &v); err != nil {
+		return err
+	}
+	o, err := rebuild(CIPSO(v), parseCIPSO)
+	if err != nil {
+		return err
+	}
+	*c = o.(CIPSO)
+	return nil
+}
+
+// MarshalJSON encodes the option as a JSON object.
+func (es ESec) MarshalJSON() ([]byte, error) {
+	type plain ESec
+	return marshalOptionJSON(ExtendedSecurity, es.Length(), plain(es))
+}
+
+// UnmarshalJSON decodes the option from a JSON object.
+func (es *ESec) UnmarshalJSON(b []byte) error {
+	type plain ESec
+	var v plain
+	if _, err := unmarshalOptionJSON(b, &v); err != nil {
+		return err
+	}
+	o, err := rebuild(ESec(v), parseExtendedSecurity)
+	if err != nil {
+		return err
+	}
+	*es = o.(ESec)
+	return nil
+}
+
+// MarshalJSON encodes the option as a JSON object.
+func (m MTUProbe) MarshalJSON() ([]byte, error) {
+	type plain MTUProbe
+	return marshalOptionJSON(MTUProbeOption, m.Length(), plain(m))
+}
+
+// UnmarshalJSON decodes the option from a JSON object.
+func (m *MTUProbe) UnmarshalJSON(b []byte) error {
+	type plain MTUProbe
+	var v plain
+	if _, err := unmarshalOptionJSON(b, &v); err != nil {
+		return err
+	}
+	o, err := rebuild(MTUProbe(v), parseMTUProbe)
+	if err != nil {
+		return err
+	}
+	*m = o.(MTUProbe)
+	return nil
+}
+
+// MarshalJSON encodes the option as a JSON object.
+func (m MTUReply) MarshalJSON() ([]byte, error) {
+	type plain MTUReply
+	return marshalOptionJSON(MTUReplyOption, m.Length(), plain(m))
+}
+
+// UnmarshalJSON decodes the option from a JSON object.
+func (m *MTUReply) UnmarshalJSON(b []byte) error {
+	type plain MTUReply
+	var v plain
+	if _, err := unmarshalOptionJSON(b, &v); err != nil {
+		return err
+	}
+	o, err := rebuild(MTUReply(v), parseMTUReply)
+	if err != nil {
+		return err
+	}
+	*m = o.(MTUReply)
+	return nil
+}
+
+// MarshalJSON encodes the option as a JSON object.
+func (tr Traceroute) MarshalJSON() ([]byte, error) {
+	type plain Traceroute
+	return marshalOptionJSON(TracerouteOption, tr.Length(), plain(tr))
+}
+
+// UnmarshalJSON decodes the option from a JSON object.
+func (tr *Traceroute) UnmarshalJSON(b []byte) error {
+	type plain Traceroute
+	var v plain
+	if _, err := unmarshalOptionJSON(b, &v); err != nil {
+		return err
+	}
+	o, err := rebuild(Traceroute(v), parseTraceroute)
+	if err != nil {
+		return err
+	}
+	*tr = o.(Traceroute)
+	return nil
+}
+
+// MarshalJSON encodes the option as a JSON object.
+func (qs QuickStart) MarshalJSON() ([]byte, error) {
+	type plain QuickStart
+	return marshalOptionJSON(QuickStartOption, qs.Length(), plain(qs))
+}
+
+// UnmarshalJSON decodes the option from a JSON object.
+func (qs *QuickStart) UnmarshalJSON(b []byte) error {
+	type plain QuickStart
+	var v plain
+	if _, err := unmarshalOptionJSON(b, &v); err != nil {
+		return err
+	}
+	o, err := rebuild(QuickStart(v), parseQuickStart)
+	if err != nil {
+		return err
+	}
+	*qs = o.(QuickStart)
+	return nil
+}
+
+// MarshalJSON encodes the option as a JSON object.
+func (exp Experimental) MarshalJSON() ([]byte, error) {
+	type plain Experimental
+	return marshalOptionJSON(exp.Type(), exp.Length(), plain(exp))
+}
+
+// UnmarshalJSON decodes the option from a JSON object. The type must be one
+// of the experimental types.
+func (exp *Experimental) UnmarshalJSON(b []byte) error {
+	type plain Experimental
+	var v plain
+	t, err := unmarshalOptionJSON(b, &v)
+	if err != nil {
+		return err
+	}
+	if !isExperimental(t) {
+		return fmt.Errorf("%w: %v is not an experimental option", ErrOptionType, t)
+	}
+	v.option.otype = t
+	o, err := rebuild(Experimental(v), parseExperimental)
+	if err != nil {
+		return err
+	}
+	*exp = o.(Experimental)
+	return nil
+}
+
+// MarshalJSON encodes the option as a JSON object.
+func (raw RawOption) MarshalJSON() ([]byte, error) {
+	type plain RawOption
+	return marshalOptionJSON(raw.Type(), raw.Length(), plain(raw))
+}
+
+// UnmarshalJSON decodes the option from a JSON object.
+func (raw *RawOption) UnmarshalJSON(b []byte) error {
+	type plain RawOption
+	var v plain
+	t, err := unmarshalOptionJSON(b, &v)
+	if err != nil {
+		return err
+	}
+	v.option.otype = t
+	o, err := rebuild(RawOption(v), parseRaw)
+	if err != nil {
+		return err
+	}
+	*raw = o.(RawOption)
+	return nil
+}
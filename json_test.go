@@ -0,0 +1,63 @@
+package ipv4opt_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func TestOptionsJSON(t *testing.T) {
+	var data []byte
+	data = append(data, tsPreSpec[:12]...)
+	data = append(data, sidTest...)
+	data = append(data, ipv4opt.NoOperation)
+	data = append(data, 148, 4, 0, 0)
+	data = append(data, 7, 7, 4, 0, 0, 0, 0)
+	data = append(data, 222, 4, 1, 2)
+	data = append(data, ipv4opt.EndOfOptionList)
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	b, err := json.Marshal(ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`"Type":"TS"`, `"Addr":"66.109.38.50"`, `"Clock":"13:06:55.085"`, `"Type":"RTRALT"`, `"Type":"EXP222"`} {
+		if !strings.Contains(string(b), s) {
+			t.Fatalf("Expected %s in %s", s, b)
+		}
+	}
+	var decoded ipv4opt.Options
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	expected, err := ops.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := decoded.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, expected) {
+		t.Fatalf("JSON round trip, Expected(%v), Got(%v)", expected, got)
+	}
+}
+
+func TestOptionJSONErrors(t *testing.T) {
+	tests := []string{
+		`{"Type":"TS","Pointer":5}`,
+		`{"Type":"RR","Routes":["1.2.3"]}`,
+		`{"Type":"bogus"}`,
+	}
+	for _, test := range tests {
+		var rr ipv4opt.RR
+		if err := json.Unmarshal([]byte(test), &rr); err == nil {
+			t.Fatalf("Expected error decoding %s", test)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package ipv4opt
+
+// LazyOptions is a list of IPv4 options that are only decoded when they are
+// requested. The offsets of the options are indexed on first access.
+type LazyOptions struct {
+	raw     []byte
+	offsets []int
+	types   []OptionType
+	indexed bool
+	err     error
+}
+
+// Lazy returns a LazyOptions for the options in opts.
+func Lazy(opts []byte) *LazyOptions {
+	return &LazyOptions{raw: opts}
+}
+
+// index records the offset and type of every option using only the type and
+// length fields. Like Parse, it stops after the first EndOfOptionList, so
+// the padding after it is not indexed.
+func (l *LazyOptions) index() error {
+	if l.indexed {
+		return l.err
+	}
+	l.indexed = true
+	if len(l.raw) > MaxOptionsLen {
+		l.err = ErrOptionDataTooLarge
+		return l.err
+	}
+	for i := 0; i < len(l.raw); {
+		t := OptionType(l.raw[i])
+		n := 1
+		if !isPaddingType(t) {
+			length, err := optionLength(l.raw[i:], 2)
+			if err != nil {
+				l.err = &OptionError{Type: t, Offset: i, Err: err}
+				return l.err
+			}
+			n = length
+		}
+		l.offsets = append(l.offsets, i)
+		l.types = append(l.types, t)
+		if t == EndOfOptionList {
+			break
+		}
+		i += n
+	}
+	return nil
+}
+
+// Types returns the types of the options in order. If the options are
+// malformed only the types before the malformed option are returned.
+func (l *LazyOptions) Types() []OptionType {
+	l.index()
+	types := make([]OptionType, len(l.types))
+	copy(types, l.types)
+	return types
+}
+
+// Get decodes and returns the first option of type t. ErrOptionNotFound is
+// returned if there is no such option.
+func (l *LazyOptions) Get(t OptionType) (IPOption, error) {
+	err := l.index()
+	for i, ot := range l.types {
+		if ot == t {
+			opt, err := parserFor(t)(l.raw[l.offsets[i]:])
+			if err != nil {
+				return nil, &OptionError{Type: t, Offset: l.offsets[i], Err: err}
+			}
+			return opt, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, ErrOptionNotFound
+}
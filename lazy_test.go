@@ -0,0 +1,54 @@
+package ipv4opt_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func TestLazyGet(t *testing.T) {
+	data := []byte{7, 10, 4, 0, 0, 0, 0, 0, 0, 0}
+	data = append(data, secTest...)
+	if _, err := ipv4opt.Parse(data); err == nil {
+		t.Fatalf("Expected bad RR option to fail a full parse")
+	}
+	lazy := ipv4opt.Lazy(data)
+	expected := []ipv4opt.OptionType{ipv4opt.RecordRoute, ipv4opt.Security}
+	if !reflect.DeepEqual(lazy.Types(), expected) {
+		t.Fatalf("Wrong types, Expected(%v), Got(%v)", expected, lazy.Types())
+	}
+	opt, err := lazy.Get(ipv4opt.Security)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sec := opt.(ipv4opt.Sec); sec.Level != ipv4opt.Secret {
+		t.Fatalf("Wrong level, Expected(%v), Got(%v)", ipv4opt.Secret, sec.Level)
+	}
+	if _, err := lazy.Get(ipv4opt.InternetTimestamp); err != ipv4opt.ErrOptionNotFound {
+		t.Fatalf("Wrong error, Expected(%v), Got(%v)", ipv4opt.ErrOptionNotFound, err)
+	}
+}
+
+func TestLazyStopsAtEOOL(t *testing.T) {
+	for _, data := range [][]byte{
+		append(append([]byte{}, sidTest...), 0, 0, 0, 0),
+		append(append([]byte{}, sidTest...), 0, 7, 40, 1, 2),
+	} {
+		ops, err := ipv4opt.Parse(data)
+		if err != nil {
+			t.Fatalf("Failed to parse test data: %v", err)
+		}
+		lazy := ipv4opt.Lazy(data)
+		expected := []ipv4opt.OptionType{ipv4opt.StreamIdentifier, ipv4opt.EndOfOptionList}
+		if !reflect.DeepEqual(lazy.Types(), expected) || len(ops) != len(expected) {
+			t.Fatalf("Wrong types for %v, Expected(%v), Got(%v)", data, expected, lazy.Types())
+		}
+		if _, err := lazy.Get(ipv4opt.StreamIdentifier); err != nil {
+			t.Fatalf("Failed to get option from %v: %v", data, err)
+		}
+		if _, err := lazy.Get(ipv4opt.RecordRoute); err != ipv4opt.ErrOptionNotFound {
+			t.Fatalf("Wrong error, Expected(%v), Got(%v)", ipv4opt.ErrOptionNotFound, err)
+		}
+	}
+}
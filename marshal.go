@@ -0,0 +1,398 @@
+package ipv4opt
+
+// padLen rounds n up to the next 32-bit boundary.
+func padLen(n int) int {
+	return (n + 3) &^ 3
+}
+
+// length returns the total length of the options without padding to a 32-bit
+// boundary.
+func (o Options) length() int {
+	var n int
+	for _, opt := range o {
+		n += opt.Length()
+	}
+	return n
+}
+
+// Minimize returns an equivalent list of options with all NoOperation and
+// EndOfOptionList padding removed, leaving only the trailing alignment
+// required by the IHL field to be added when the options are written out.
+func (o Options) Minimize() (Options, error) {
+	var min Options
+	for _, opt := range o {
+		if isPadding(opt) {
+			continue
+		}
+		min = append(min, opt)
+	}
+	if padLen(min.length()) > MaxOptionsLen {
+		return nil, ErrOptionDataTooLarge
+	}
+	return min, nil
+}
+
+// Canonicalize returns the options with all NoOperation and EndOfOptionList
+// padding removed and a single EndOfOptionList added after the last option
+// unless it already ends on a 32-bit boundary.
+func (o Options) Canonicalize() Options {
+	c := make(Options, 0, len(o)+1)
+	for _, opt := range o {
+		if !isPadding(opt) {
+			c = append(c, opt)
+		}
+	}
+	if c.length()%4 != 0 {
+		c = append(c, EOOList{option{otype: EndOfOptionList, length: 1, data: []byte{EndOfOptionList}}})
+	}
+	return c
+}
+
+// CanAdd reports whether opt can be appended to the list without the padded
+// options exceeding MaxOptionsLen.
+func (o Options) CanAdd(opt IPOption) bool {
+	return padLen(o.length()+opt.Length()) <= MaxOptionsLen
+}
+
+// MarshalEach returns the wire bytes of each option in the list separately,
+// without any padding between or after them.
+func (o Options) MarshalEach() ([][]byte, error) {
+	out := make([][]byte, 0, len(o))
+	for _, opt := range o {
+		b, err := marshalOption(opt)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, nil
+}
+
+// Marshal returns the wire format of the options, padded with
+// EndOfOptionList bytes to a 32-bit boundary.
+func (o Options) Marshal() ([]byte, error) {
+	return o.AppendTo(nil)
+}
+
+// AppendTo appends the wire format of the options, padded with
+// EndOfOptionList bytes to a 32-bit boundary, to dst and returns the extended
+// slice. Nothing is allocated when dst has room for the options. On error dst
+// is returned unchanged.
+func (o Options) AppendTo(dst []byte) ([]byte, error) {
+	b, err := o.appendUnpadded(dst)
+	if err != nil {
+		return dst, err
+	}
+	for n := len(b) - len(dst); n < padLen(n); n++ {
+		b = append(b, EndOfOptionList)
+	}
+	return b, nil
+}
+
+// appendUnpadded appends the wire format of the options to dst without any
+// padding after them.
+func (o Options) appendUnpadded(dst []byte) ([]byte, error) {
+	b := dst
+	for _, opt := range o {
+		var err error
+		if b, err = appendOption(b, opt); err != nil {
+			return dst, err
+		}
+	}
+	if len(b)-len(dst) > MaxOptionsLen {
+		return dst, ErrOptionDataTooLarge
+	}
+	return b, nil
+}
+
+// MarshalCanonical returns the wire format of the canonicalized options,
+// padded with zeros to a 32-bit boundary.
+func (o Options) MarshalCanonical() ([]byte, error) {
+	return o.Canonicalize().Marshal()
+}
+
+type marshaler interface {
+	Marshal() ([]byte, error)
+}
+
+type appender interface {
+	AppendTo(dst []byte) ([]byte, error)
+}
+
+// appendOption appends the wire format of opt to dst, using its AppendTo
+// method if it has one and its data otherwise.
+func appendOption(dst []byte, opt IPOption) ([]byte, error) {
+	if a, ok := opt.(appender); ok {
+		return a.AppendTo(dst)
+	}
+	data := dataOf(opt)
+	if len(data) != opt.Length() {
+		return dst, ErrInvalidOptionLength
+	}
+	return append(dst, data...), nil
+}
+
+// marshalOption returns the wire format of opt.
+func marshalOption(opt IPOption) ([]byte, error) {
+	return appendOption(nil, opt)
+}
+
+// Marshal returns the wire format of the option.
+func (opt EOOList) Marshal() ([]byte, error) {
+	return opt.AppendTo(nil)
+}
+
+// AppendTo appends the wire format of the option to dst.
+func (opt EOOList) AppendTo(dst []byte) ([]byte, error) {
+	return append(dst, EndOfOptionList), nil
+}
+
+// Marshal returns the wire format of the option.
+func (opt NoOp) Marshal() ([]byte, error) {
+	return opt.AppendTo(nil)
+}
+
+// AppendTo appends the wire format of the option to dst.
+func (opt NoOp) AppendTo(dst []byte) ([]byte, error) {
+	return append(dst, NoOperation), nil
+}
+
+// Marshal returns the wire format of the option built from its fields.
+func (s Sec) Marshal() ([]byte, error) {
+	return s.AppendTo(nil)
+}
+
+// AppendTo appends the wire format of the option built from its fields to
+// dst.
+func (s Sec) AppendTo(dst []byte) ([]byte, error) {
+	return append(dst,
+		Security, securityOpLen,
+		byte(s.Level>>8), byte(s.Level),
+		byte(s.Compartment>>8), byte(s.Compartment),
+		byte(s.Restriction>>8), byte(s.Restriction),
+		byte(s.TCC>>16), byte(s.TCC>>8), byte(s.TCC),
+	), nil
+}
+
+// Marshal returns the wire format of the option built from its fields.
+func (l AddressList) Marshal() ([]byte, error) {
+	return l.AppendTo(nil)
+}
+
+// AppendTo appends the wire format of the option built from its fields to
+// dst.
+func (l AddressList) AppendTo(dst []byte) ([]byte, error) {
+	if 3+4*len(l.Routes) > MaxOptionsLen {
+		return dst, ErrOptionDataTooLarge
+	}
+	return l.encode(dst), nil
+}
+
+// Marshal returns the wire format of the option built from its type and
+// value.
+func (u UInt16Option) Marshal() ([]byte, error) {
+	return u.AppendTo(nil)
+}
+
+// AppendTo appends the wire format of the option built from its type and
+// value to dst.
+func (u UInt16Option) AppendTo(dst []byte) ([]byte, error) {
+	return appendUInt16(dst, u.otype, u.Value), nil
+}
+
+// Marshal returns the wire format of the option built from its ID. Any
+// padding carried in Raw before the ID is written too.
+func (s StreamID) Marshal() ([]byte, error) {
+	return s.AppendTo(nil)
+}
+
+// AppendTo appends the wire format of the option built from its ID to dst,
+// keeping any padding carried in Raw before the ID.
+func (s StreamID) AppendTo(dst []byte) ([]byte, error) {
+	if len(s.Raw) <= 2 {
+		return appendUInt16(dst, StreamIdentifier, s.ID), nil
+	}
+	if 2+len(s.Raw) > MaxOptionsLen {
+		return dst, ErrOptionDataTooLarge
+	}
+	dst = append(dst, byte(StreamIdentifier), byte(2+len(s.Raw)))
+	dst = append(dst, s.Raw[:len(s.Raw)-2]...)
+	return append(dst, byte(s.ID>>8), byte(s.ID)), nil
+}
+
+// Marshal returns the wire format of the option built from its fields.
+func (ts TS) Marshal() ([]byte, error) {
+	return ts.AppendTo(nil)
+}
+
+// AppendTo appends the wire format of the option built from its fields to
+// dst.
+func (ts TS) AppendTo(dst []byte) ([]byte, error) {
+	if 4+ts.entryLen()*len(ts.Stamps) > MaxOptionsLen {
+		return dst, ErrOptionDataTooLarge
+	}
+	return ts.encode(dst), nil
+}
+
+// WireLength returns the length of the options once padded to a 32-bit
+// boundary, as they are carried in the header.
+func (o Options) WireLength() int {
+	return padLen(o.length())
+}
+
+// IHLWords returns the IHL field of a header carrying the options: the
+// header length, including the padded options, in 32-bit words.
+func (o Options) IHLWords() int {
+	return (headerLen + o.WireLength()) / 4
+}
+
+// Fits reports whether the padded options fit within MaxOptionsLen, so that
+// IHLWords is at most 15.
+func (o Options) Fits() bool {
+	return o.WireLength() <= MaxOptionsLen
+}
+
+// BudgetUsed returns the fraction of MaxOptionsLen taken up by the options
+// once padded to a 32-bit boundary.
+func (o Options) BudgetUsed() float64 {
+	return float64(padLen(o.length())) / float64(MaxOptionsLen)
+}
+
+// Marshal returns the wire format of the option built from its value.
+func (ra RouterAlert) Marshal() ([]byte, error) {
+	return ra.AppendTo(nil)
+}
+
+// AppendTo appends the wire format of the option built from its value to dst.
+func (ra RouterAlert) AppendTo(dst []byte) ([]byte, error) {
+	return appendUInt16(dst, RouterAlertOption, ra.Value), nil
+}
+
+// Marshal returns the wire format of the option built from its DOI and tags.
+func (c CIPSO) Marshal() ([]byte, error) {
+	return c.AppendTo(nil)
+}
+
+// AppendTo appends the wire format of the option built from its DOI and tags
+// to dst.
+func (c CIPSO) AppendTo(dst []byte) ([]byte, error) {
+	b := c.encode(dst)
+	if len(b)-len(dst) > MaxOptionsLen {
+		return dst, ErrOptionDataTooLarge
+	}
+	return b, nil
+}
+
+// Marshal returns the wire format of the option built from its fields.
+func (es ESec) Marshal() ([]byte, error) {
+	return es.AppendTo(nil)
+}
+
+// AppendTo appends the wire format of the option built from its fields to
+// dst.
+func (es ESec) AppendTo(dst []byte) ([]byte, error) {
+	if extendedSecurityMinLen+len(es.Info) > MaxOptionsLen {
+		return dst, ErrOptionDataTooLarge
+	}
+	dst = append(dst, ExtendedSecurity, byte(extendedSecurityMinLen+len(es.Info)), es.Format)
+	return append(dst, es.Info...), nil
+}
+
+// Marshal returns the wire format of the option built from its fields.
+func (bs BasicSec) Marshal() ([]byte, error) {
+	return bs.AppendTo(nil)
+}
+
+// AppendTo appends the wire format of the option built from its fields to
+// dst.
+func (bs BasicSec) AppendTo(dst []byte) ([]byte, error) {
+	if basicSecurityMinLen+len(bs.Authorities) > MaxOptionsLen {
+		return dst, ErrOptionDataTooLarge
+	}
+	dst = append(dst, Security, byte(basicSecurityMinLen+len(bs.Authorities)), byte(bs.Classification))
+	return append(dst, bs.Authorities...), nil
+}
+
+// Marshal returns the wire format of the option built from its MTU.
+func (m MTUProbe) Marshal() ([]byte, error) {
+	return m.AppendTo(nil)
+}
+
+// AppendTo appends the wire format of the option built from its MTU to dst.
+func (m MTUProbe) AppendTo(dst []byte) ([]byte, error) {
+	return appendUInt16(dst, MTUProbeOption, m.MTU), nil
+}
+
+// Marshal returns the wire format of the option built from its MTU.
+func (m MTUReply) Marshal() ([]byte, error) {
+	return m.AppendTo(nil)
+}
+
+// AppendTo appends the wire format of the option built from its MTU to dst.
+func (m MTUReply) AppendTo(dst []byte) ([]byte, error) {
+	return appendUInt16(dst, MTUReplyOption, m.MTU), nil
+}
+
+// Marshal returns the wire format of the option built from its fields.
+func (tr Traceroute) Marshal() ([]byte, error) {
+	return tr.AppendTo(nil)
+}
+
+// AppendTo appends the wire format of the option built from its fields to
+// dst.
+func (tr Traceroute) AppendTo(dst []byte) ([]byte, error) {
+	return append(dst,
+		TracerouteOption, tracerouteOptLen,
+		byte(tr.ID>>8), byte(tr.ID),
+		byte(tr.OutboundHops>>8), byte(tr.OutboundHops),
+		byte(tr.ReturnHops>>8), byte(tr.ReturnHops),
+		byte(tr.Originator>>24), byte(tr.Originator>>16), byte(tr.Originator>>8), byte(tr.Originator),
+	), nil
+}
+
+// Marshal returns the wire format of the option built from its fields.
+func (qs QuickStart) Marshal() ([]byte, error) {
+	return qs.AppendTo(nil)
+}
+
+// AppendTo appends the wire format of the option built from its fields to
+// dst.
+func (qs QuickStart) AppendTo(dst []byte) ([]byte, error) {
+	return append(dst,
+		QuickStartOption, quickStartOptLen,
+		qs.Function<<4|qs.Rate&0x0F, qs.TTL,
+		byte(qs.Nonce>>22), byte(qs.Nonce>>14), byte(qs.Nonce>>6), byte(qs.Nonce<<2),
+	), nil
+}
+
+// Marshal returns the wire format of the option built from its type and
+// payload.
+func (exp Experimental) Marshal() ([]byte, error) {
+	return exp.AppendTo(nil)
+}
+
+// AppendTo appends the wire format of the option built from its type and
+// payload to dst.
+func (exp Experimental) AppendTo(dst []byte) ([]byte, error) {
+	if 2+len(exp.Value) > MaxOptionsLen {
+		return dst, ErrOptionDataTooLarge
+	}
+	dst = append(dst, byte(exp.otype), byte(2+len(exp.Value)))
+	return append(dst, exp.Value...), nil
+}
+
+// Marshal returns the wire format of the option built from its type and
+// value.
+func (raw RawOption) Marshal() ([]byte, error) {
+	return raw.AppendTo(nil)
+}
+
+// AppendTo appends the wire format of the option built from its type and
+// value to dst.
+func (raw RawOption) AppendTo(dst []byte) ([]byte, error) {
+	if 2+len(raw.Value) > MaxOptionsLen {
+		return dst, ErrOptionDataTooLarge
+	}
+	dst = append(dst, byte(raw.otype), byte(2+len(raw.Value)))
+	return append(dst, raw.Value...), nil
+}
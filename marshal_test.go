@@ -0,0 +1,280 @@
+package ipv4opt_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+var sidTest = []byte{
+	136, 4, 0x12, 0x34,
+}
+
+func optionsLen(ops ipv4opt.Options) int {
+	var n int
+	for _, opt := range ops {
+		n += opt.Length()
+	}
+	return n
+}
+
+func TestMinimize(t *testing.T) {
+	var data []byte
+	data = append(data, ipv4opt.NoOperation)
+	data = append(data, secTest...)
+	data = append(data, ipv4opt.NoOperation, ipv4opt.NoOperation)
+	data = append(data, sidTest...)
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	min, err := ops.Minimize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if optionsLen(min) >= optionsLen(ops) {
+		t.Fatalf("Options not minimized, Expected less than(%v), Got(%v)", optionsLen(ops), optionsLen(min))
+	}
+	expected := []ipv4opt.OptionType{ipv4opt.Security, ipv4opt.StreamIdentifier}
+	if len(min) != len(expected) {
+		t.Fatalf("Wrong number of options, Expected(%v), Got(%v)", len(expected), len(min))
+	}
+	for i, opt := range min {
+		if opt.Type() != expected[i] {
+			t.Fatalf("Wrong option type at %v, Expected(%v), Got(%v)", i, expected[i], opt.Type())
+		}
+	}
+}
+
+func TestCanAdd(t *testing.T) {
+	sid, err := ipv4opt.Parse(sidTest)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	for _, test := range []struct {
+		testData []byte
+		expected bool
+	}{
+		{
+			testData: tsTest2,
+			expected: true,
+		},
+		{
+			testData: append(append([]byte{}, tsTest2...), ipv4opt.NoOperation),
+			expected: false,
+		},
+	} {
+		ops, err := ipv4opt.Parse(test.testData)
+		if err != nil {
+			t.Fatalf("Failed to parse test data: %v", err)
+		}
+		if ops.CanAdd(sid[0]) != test.expected {
+			t.Fatalf("Wrong result for %v bytes, Expected(%v), Got(%v)", len(test.testData), test.expected, !test.expected)
+		}
+	}
+}
+
+func TestMarshalEach(t *testing.T) {
+	var data []byte
+	data = append(data, secTest...)
+	data = append(data, ipv4opt.NoOperation)
+	data = append(data, sidTest...)
+	data = append(data, tsPreSpec...)
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	each, err := ops.MarshalEach()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lengths := []int{11, 1, 4, 12, 1}
+	if len(each) != len(lengths) {
+		t.Fatalf("Wrong number of options, Expected(%v), Got(%v)", len(lengths), len(each))
+	}
+	for i, b := range each {
+		if len(b) != lengths[i] {
+			t.Fatalf("Wrong length at %v, Expected(%v), Got(%v)", i, lengths[i], len(b))
+		}
+	}
+}
+
+func TestBudgetUsed(t *testing.T) {
+	ops, err := ipv4opt.Parse(tsTest)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	if ops.BudgetUsed() != 1.0 {
+		t.Fatalf("Wrong budget used, Expected(%v), Got(%v)", 1.0, ops.BudgetUsed())
+	}
+	var empty ipv4opt.Options
+	if empty.BudgetUsed() != 0 {
+		t.Fatalf("Wrong budget used, Expected(%v), Got(%v)", 0, empty.BudgetUsed())
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	for _, test := range []struct {
+		testData []byte
+		expected []byte
+	}{
+		{
+			testData: rrTest,
+			expected: rrTest,
+		},
+		{
+			testData: tsTest,
+			expected: tsTest,
+		},
+		{
+			testData: tsPreSpec,
+			expected: append(append([]byte{}, tsPreSpec...), 0, 0, 0),
+		},
+		{
+			testData: append(append([]byte{}, sidTest...), ipv4opt.NoOperation),
+			expected: append(append([]byte{}, sidTest...), ipv4opt.NoOperation, 0, 0, 0),
+		},
+	} {
+		ops, err := ipv4opt.Parse(test.testData)
+		if err != nil {
+			t.Fatalf("Failed to parse test data: %v", err)
+		}
+		b, err := ops.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(b, test.expected) {
+			t.Fatalf("Wrong marshaled data, Expected(%v), Got(%v)", test.expected, b)
+		}
+	}
+}
+
+func TestMarshalSecurity(t *testing.T) {
+	sec := ipv4opt.Sec{
+		Level:       ipv4opt.TopSecret,
+		Compartment: 0x1234,
+		Restriction: 0x4142,
+	}
+	b, err := ipv4opt.Options{sec}.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 12 {
+		t.Fatalf("Wrong marshaled length, Expected(%v), Got(%v)", 12, len(b))
+	}
+	ops, err := ipv4opt.Parse(b)
+	if err != nil {
+		t.Fatalf("Failed to parse marshaled data: %v", err)
+	}
+	parsed := ops[0].(ipv4opt.Sec)
+	if parsed.Level != sec.Level || parsed.Compartment != sec.Compartment || parsed.Restriction != sec.Restriction {
+		t.Fatalf("Wrong security option, Expected(%v), Got(%v)", sec, parsed)
+	}
+}
+
+func TestMarshalCanonical(t *testing.T) {
+	tests := []struct {
+		data     []byte
+		expected []byte
+	}{
+		{[]byte{1, 1, 148, 4, 0, 0}, []byte{148, 4, 0, 0}},
+		{[]byte{1, 136, 4, 0x12, 0x34, 1, 7, 3, 4, 0, 0, 0}, []byte{136, 4, 0x12, 0x34, 7, 3, 4, 0}},
+		{[]byte{1, 7, 7, 4, 0, 0, 0, 0, 0}, []byte{7, 7, 4, 0, 0, 0, 0, 0}},
+		{[]byte{0, 0, 0, 0}, nil},
+	}
+	for _, test := range tests {
+		ops, err := ipv4opt.Parse(test.data)
+		if err != nil {
+			t.Fatalf("Failed to parse test data: %v", err)
+		}
+		c := ops.Canonicalize()
+		if n := len(c); n > 0 && optionsLen(c)%4 != 0 && c[n-1].Type() != ipv4opt.EndOfOptionList {
+			t.Fatalf("Expected an EndOfOptionList terminator, Got(%v)", c)
+		}
+		b, err := ops.MarshalCanonical()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(b, test.expected) {
+			t.Fatalf("Expected(%v), Got(%v)", test.expected, b)
+		}
+	}
+}
+
+func TestWireLength(t *testing.T) {
+	rr, err := ipv4opt.NewRecordRoute(9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sid, err := ipv4opt.Parse(sidTest)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	tests := []struct {
+		ops        ipv4opt.Options
+		wireLength int
+		ihl        int
+		fits       bool
+	}{
+		{nil, 0, 5, true},
+		{sid, 4, 6, true},
+		{ipv4opt.Options{rr}, 40, 15, true},
+		{append(ipv4opt.Options{rr}, sid...), 44, 16, false},
+	}
+	for _, test := range tests {
+		if test.ops.WireLength() != test.wireLength {
+			t.Fatalf("Wrong wire length, Expected(%v), Got(%v)", test.wireLength, test.ops.WireLength())
+		}
+		if test.ops.IHLWords() != test.ihl {
+			t.Fatalf("Wrong IHL, Expected(%v), Got(%v)", test.ihl, test.ops.IHLWords())
+		}
+		if test.ops.Fits() != test.fits {
+			t.Fatalf("Wrong fit, Expected(%v), Got(%v)", test.fits, test.ops.Fits())
+		}
+	}
+}
+
+func TestAppendTo(t *testing.T) {
+	data := append(append(append([]byte{148, 4, 0, 0, 1}, sidTest...), rrEmptyTest[:11]...), tsPreSpec[:12]...)
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	expected, err := ops.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame := []byte{0x45, 0, 0, 0}
+	b, err := ops.AppendTo(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b[:4], frame) || !bytes.Equal(b[4:], expected) {
+		t.Fatalf("Wrong appended data, Expected(%v), Got(%v)", expected, b[4:])
+	}
+	for _, opt := range ops {
+		a, ok := opt.(interface {
+			AppendTo([]byte) ([]byte, error)
+		})
+		if !ok {
+			t.Fatalf("%T has no AppendTo method", opt)
+		}
+		m, _ := ipv4opt.Options{opt}.MarshalBinary()
+		if b, err := a.AppendTo(nil); err != nil || !bytes.Equal(b, m) {
+			t.Fatalf("Wrong %T, Expected(%v), Got(%v, %v)", opt, m, b, err)
+		}
+	}
+	buf := make([]byte, 0, ipv4opt.MaxOptionsLen)
+	allocs := testing.AllocsPerRun(100, func() {
+		ops.AppendTo(buf)
+	})
+	if allocs != 0 {
+		t.Fatalf("AppendTo allocated, Expected(0), Got(%v)", allocs)
+	}
+	big := ipv4opt.Options{ops[3], ops[3], ops[3], ops[3]}
+	if b, err := big.AppendTo(frame); err != ipv4opt.ErrOptionDataTooLarge || len(b) != len(frame) {
+		t.Fatalf("Expected(%v) and unchanged dst, Got(%v, %v)", ipv4opt.ErrOptionDataTooLarge, b, err)
+	}
+}
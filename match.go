@@ -0,0 +1,98 @@
+package ipv4opt
+
+// typeSet is a set of option types.
+type typeSet [4]uint64
+
+func (s *typeSet) add(t OptionType) {
+	s[t/64] |= 1 << (t % 64)
+}
+
+// contains reports whether every type of o is in s.
+func (s *typeSet) contains(o *typeSet) bool {
+	for i := range s {
+		if o[i]&^s[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Matcher matches lists of options against a set of conditions. Conditions
+// added one after the other must all hold; Or starts a new group of
+// conditions, and the Matcher matches when any group holds. A group with no
+// conditions holds for every list.
+//
+// A Matcher is built once with Match and its methods, and can then be used
+// by many goroutines at once. Matching does not allocate.
+type Matcher struct {
+	groups []typeSet
+}
+
+// Match returns a Matcher with no conditions.
+func Match() *Matcher {
+	return &Matcher{groups: make([]typeSet, 1)}
+}
+
+// HasType requires an option of type t in the list.
+func (m *Matcher) HasType(t OptionType) *Matcher {
+	m.groups[len(m.groups)-1].add(t)
+	return m
+}
+
+// Or starts a new group of conditions.
+func (m *Matcher) Or() *Matcher {
+	m.groups = append(m.groups, typeSet{})
+	return m
+}
+
+// Matches reports whether opts matches.
+func (m *Matcher) Matches(opts Options) bool {
+	var present typeSet
+	for _, opt := range opts {
+		present.add(opt.Type())
+	}
+	return m.matches(&present)
+}
+
+// MatchesBytes reports whether the options encoded in opts match, without
+// decoding them. Only the type and length fields are read; scanning stops at
+// the end of the option list or at the first malformed option, and the
+// options before it are matched. A malformed option is therefore never
+// matched, so a Matcher used to deny options lets it through; use
+// MatchesBytesStrict to reject malformed input.
+func (m *Matcher) MatchesBytes(opts []byte) bool {
+	ok, _ := m.MatchesBytesStrict(opts)
+	return ok
+}
+
+// MatchesBytesStrict is like MatchesBytes, but returns an *OptionError
+// alongside the result for the options before it when opts holds a
+// malformed option.
+func (m *Matcher) MatchesBytesStrict(opts []byte) (bool, error) {
+	var present typeSet
+	for i := 0; i < len(opts); {
+		t := OptionType(opts[i])
+		length := 1
+		if !isPaddingType(t) {
+			var err error
+			if length, err = optionLength(opts[i:], 2); err != nil {
+				return m.matches(&present), &OptionError{Type: t, Offset: i, Err: err}
+			}
+		}
+		present.add(t)
+		if t == EndOfOptionList {
+			break
+		}
+		i += length
+	}
+	return m.matches(&present), nil
+}
+
+func (m *Matcher) matches(present *typeSet) bool {
+	for i := range m.groups {
+		if present.contains(&m.groups[i]) {
+			return true
+		}
+	}
+	return false
+}
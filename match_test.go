@@ -0,0 +1,68 @@
+package ipv4opt_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func TestMatcher(t *testing.T) {
+	sourceRouted := ipv4opt.Match().
+		HasType(ipv4opt.LooseSourceRecordRoute).
+		Or().
+		HasType(ipv4opt.StrictSourceRecordRoute)
+	both := ipv4opt.Match().HasType(ipv4opt.RouterAlertOption).HasType(ipv4opt.RecordRoute)
+	tests := []struct {
+		name   string
+		m      *ipv4opt.Matcher
+		data   []byte
+		result bool
+	}{
+		{"lsrr", sourceRouted, []byte{1, 131, 7, 4, 192, 0, 2, 1}, true},
+		{"ssrr", sourceRouted, []byte{137, 7, 4, 192, 0, 2, 1, 0}, true},
+		{"record route", sourceRouted, []byte{7, 7, 4, 0, 0, 0, 0, 0}, false},
+		{"after eool", sourceRouted, []byte{0, 131, 7, 4, 192, 0, 2, 1}, false},
+		{"all of group", both, []byte{148, 4, 0, 0, 7, 7, 4, 0, 0, 0, 0}, true},
+		{"part of group", both, []byte{148, 4, 0, 0}, false},
+		{"no conditions", ipv4opt.Match(), []byte{1}, true},
+	}
+	for _, test := range tests {
+		if got := test.m.MatchesBytes(test.data); got != test.result {
+			t.Fatalf("%s: Wrong bytes match, Expected(%v), Got(%v)", test.name, test.result, got)
+		}
+		ops, err := ipv4opt.Parse(test.data)
+		if err != nil {
+			t.Fatalf("%s: Failed to parse test data: %v", test.name, err)
+		}
+		if got := test.m.Matches(ops); got != test.result {
+			t.Fatalf("%s: Wrong match, Expected(%v), Got(%v)", test.name, test.result, got)
+		}
+	}
+	// A truncated source route is not matched by MatchesBytes, so a deny
+	// rule must check the error of MatchesBytesStrict.
+	malformed := []byte{131, 9, 4, 192}
+	if sourceRouted.MatchesBytes(malformed) {
+		t.Fatalf("Malformed option matched")
+	}
+	ok, err := sourceRouted.MatchesBytesStrict(malformed)
+	if ok || !errors.Is(err, ipv4opt.ErrTruncatedOption) {
+		t.Fatalf("Wrong strict match, Expected(false %v), Got(%v %v)", ipv4opt.ErrTruncatedOption, ok, err)
+	}
+	var oe *ipv4opt.OptionError
+	if !errors.As(err, &oe) || oe.Type != ipv4opt.LooseSourceRecordRoute || oe.Offset != 0 {
+		t.Fatalf("Wrong option error, Got(%v)", err)
+	}
+	if ok, err := sourceRouted.MatchesBytesStrict([]byte{131, 7, 4, 192, 0, 2, 1, 7, 1}); !ok || !errors.Is(err, ipv4opt.ErrInvalidOptionLength) {
+		t.Fatalf("Wrong strict match after valid option, Expected(true %v), Got(%v %v)", ipv4opt.ErrInvalidOptionLength, ok, err)
+	}
+	data := []byte{1, 131, 7, 4, 192, 0, 2, 1}
+	ops, _ := ipv4opt.Parse(data)
+	allocs := testing.AllocsPerRun(100, func() {
+		sourceRouted.MatchesBytes(data)
+		sourceRouted.Matches(ops)
+	})
+	if allocs != 0 {
+		t.Fatalf("Matching allocated, Expected(0), Got(%v)", allocs)
+	}
+}
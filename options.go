@@ -1,8 +1,10 @@
 package ipv4opt
 
 import (
+	"encoding/binary"
 	"fmt"
 	"net"
+	"sync"
 )
 
 //OptionType repesents and option.
@@ -72,6 +74,65 @@ const (
 	StreamIdentifier = 136
 	//InternetTimestamp records timestamps along the path of the datagram.
 	InternetTimestamp = 68
+	// RouterAlertOption alerts transit routers to more closely examine the
+	// contents of the datagram (RFC 2113).
+	RouterAlertOption = 148
+	// CommercialSecurity carries a Commercial IP Security Option (CIPSO)
+	// label (FIPS 188).
+	CommercialSecurity = 134
+	// ExtendedSecurity carries additional security labeling information
+	// defined by the authorities in a basic security option (RFC 1108).
+	ExtendedSecurity = 133
+	// MTUProbeOption asks the destination to report the smallest MTU on the
+	// path (RFC 1063).
+	MTUProbeOption = 11
+	// MTUReplyOption reports the MTU measured by an MTU probe (RFC 1063).
+	MTUReplyOption = 12
+	// TracerouteOption asks routers to report the datagram's path back to
+	// its originator (RFC 1393).
+	TracerouteOption = 82
+	// QuickStartOption requests a sending rate along the path (RFC 4782).
+	QuickStartOption = 25
+	// ExperimentalOption30 is an option type reserved for experiments
+	// (RFC 4727).
+	ExperimentalOption30 = 30
+	// ExperimentalOption94 is an option type reserved for experiments
+	// (RFC 4727).
+	ExperimentalOption94 = 94
+	// ExperimentalOption158 is an option type reserved for experiments
+	// (RFC 4727).
+	ExperimentalOption158 = 158
+	// ExperimentalOption222 is an option type reserved for experiments
+	// (RFC 4727).
+	ExperimentalOption222 = 222
+
+	// The following options are historical and are decoded as RawOption
+	// unless noted otherwise. They still turn up in old captures. EIP,
+	// ADDEXT, SDB, DPS and UMP were deprecated by RFC 6814; the others were
+	// registered for experiments that never saw wide use.
+
+	// ZSUOption is the experimental measurement option.
+	ZSUOption = 10
+	// EncodeOption is the ENCODE option.
+	EncodeOption = 15
+	// VISAOption is the experimental access control option.
+	VISAOption = 142
+	// IMITDOption is the IMI traffic descriptor option.
+	IMITDOption = 144
+	// EIPOption is the Extended Internet Protocol option (RFC 1385).
+	EIPOption = 145
+	// AddressExtensionOption is the address extension option of IPv7.
+	AddressExtensionOption = 147
+	// SDBOption is the selective directed broadcast option (RFC 1770),
+	// decoded as SDB.
+	SDBOption = 149
+	// DPSOption is the dynamic packet state option.
+	DPSOption = 151
+	// UMPOption is the upstream multicast packet option, decoded as UMP.
+	UMPOption = 152
+	// FINNOption is the experimental flow control option.
+	FINNOption = 205
+
 	//MaxOptionsLen is the maximum length of an IPv4 option section.
 	MaxOptionsLen int = 40 // 60 Byte maximum size - 20 bytes for manditory fields
 
@@ -124,13 +185,40 @@ var (
 	//ErrOptionDataTooLarge is returned when the length of the option data is
 	//greater than the maximum option size.
 	ErrOptionDataTooLarge = fmt.Errorf("The length of the options data is larger than the max options length")
-	//ErrOptionType is returned when an invalid option type is found.
+	//ErrOptionType is returned when an invalid option type is found. Options
+	//of unknown types are only invalid if they can not be framed by their
+	//length field.
 	ErrOptionType = fmt.Errorf("Invalid option type")
 	//ErrIncorrectRRLength is returned when an RR option has route data with a length
 	//that is not a multiple of 4.
 	ErrIncorrectRRLength = fmt.Errorf("The length of the RR data is not a multiple of 4")
+	// ErrInvalidOptionLength is returned when the length of an option does
+	// not agree with its data.
+	ErrInvalidOptionLength = fmt.Errorf("Invalid option length")
+	// ErrOptionNotFound is returned when a requested option is not present.
+	ErrOptionNotFound = fmt.Errorf("Option not found")
+	// ErrTruncatedOption is returned when an option extends past the end of
+	// the options data. It wraps ErrInvalidOptionLength.
+	ErrTruncatedOption = fmt.Errorf("%w: option extends past the end of the options data", ErrInvalidOptionLength)
 )
 
+// OptionError describes a failure to parse the option at Offset in the
+// options data.
+type OptionError struct {
+	Type   OptionType
+	Offset int
+	Err    error
+}
+
+func (e *OptionError) Error() string {
+	return fmt.Sprintf("option %v at offset %d: %v", e.Type, e.Offset, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *OptionError) Unwrap() error {
+	return e.Err
+}
+
 type option struct {
 	otype  OptionType
 	length int
@@ -145,15 +233,56 @@ func (o option) Length() int {
 	return o.length
 }
 
+// Data returns a copy of the wire format of the option as it was parsed,
+// including the type and length bytes.
 func (o option) Data() []byte {
+	if o.data == nil {
+		return nil
+	}
+	b := make([]byte, len(o.data))
+	copy(b, o.data)
+	return b
+}
+
+// DataUnsafe returns the wire format of the option as it was parsed without
+// copying it. The result must not be modified.
+func (o option) DataUnsafe() []byte {
 	return o.data
 }
 
-//IPOption is the interface for an IPv4 option.
+// Payload returns a copy of the bytes of the option after the type and length
+// bytes. Single byte options have no payload.
+func (o option) Payload() []byte {
+	if len(o.data) <= 2 {
+		return nil
+	}
+	b := make([]byte, len(o.data)-2)
+	copy(b, o.data[2:])
+	return b
+}
+
+//IPOption is the interface for an IPv4 option. Data returns the whole wire
+// format of the option and Payload the bytes after the type and length.
 type IPOption interface {
 	Type() OptionType
 	Length() int
 	Data() []byte
+	Payload() []byte
+}
+
+// unsafeDataer is implemented by options that can return their data without
+// copying it.
+type unsafeDataer interface {
+	DataUnsafe() []byte
+}
+
+// dataOf returns the data of opt, without copying it when opt allows. The
+// result must not be modified.
+func dataOf(opt IPOption) []byte {
+	if d, ok := opt.(unsafeDataer); ok {
+		return d.DataUnsafe()
+	}
+	return opt.Data()
 }
 
 //Sec is the ipv4 security option
@@ -171,7 +300,7 @@ func parseSecurity(data []byte) (IPOption, error) {
 	var so Sec
 	so.option.otype = Security
 	if len(data) < securityOpLen {
-		return nil, fmt.Errorf("security option data too short %v", data)
+		return nil, ErrTruncatedOption
 	}
 	so.option.length = securityOpLen
 	so.option.data = make([]byte, 11, 11)
@@ -187,66 +316,327 @@ func parseSecurity(data []byte) (IPOption, error) {
 	so.Restriction |= SecurityHandlingRestriction(data[6]) << 8
 	so.Restriction |= SecurityHandlingRestriction(data[7])
 
-	so.TCC |= SecurityTCC(data[6]) << 16
+	so.TCC |= SecurityTCC(data[8]) << 16
 	so.TCC |= SecurityTCC(data[9]) << 8
 	so.TCC |= SecurityTCC(data[10])
 
 	return so, nil
 }
 
-//RR is an ipv4 record route option
-type RR struct {
+// AddressList is the layout shared by the options that carry a list of
+// addresses and a pointer to the next of them: the route options, SDB and
+// UMP.
+type AddressList struct {
 	option
 	Pointer byte
 	Routes  []Route
 }
 
+// parseAddressList parses the address list option at the start of data.
+func parseAddressList(data []byte) (AddressList, error) {
+	var l AddressList
+	if err := l.decode(data); err != nil {
+		return AddressList{}, err
+	}
+	return l, nil
+}
+
+// decode sets l to the address list option at the start of data, reusing
+// the storage left by Reset when it is large enough. l is left unchanged on
+// error.
+func (l *AddressList) decode(data []byte) error {
+	length, err := optionLength(data, 3)
+	if err != nil {
+		return err
+	}
+	if (length-3)%4 != 0 {
+		return ErrIncorrectRRLength
+	}
+	l.option.otype = OptionType(data[0])
+	l.option.length = length
+	l.option.data = append(reuse(l.option.data, length), data[:length]...)
+
+	l.Pointer = l.option.data[2]
+	l.Routes = reuse(l.Routes, (length-3)/4)
+	for i := 3; i < l.option.length; i += 4 {
+		l.Routes = append(l.Routes, Route(binary.BigEndian.Uint32(l.option.data[i:])))
+	}
+	return nil
+}
+
+// Reset clears the option but keeps the storage of its data and routes, so
+// that a value kept in a sync.Pool can be filled again by UnmarshalBinary
+// without allocating. Copies of the option must no longer be used, as they
+// share that storage.
+func (l *AddressList) Reset() {
+	*l = AddressList{
+		option: option{data: l.option.data[:0]},
+		Routes: l.Routes[:0],
+	}
+}
+
+//RR is an ipv4 record route option
+type RR struct {
+	AddressList
+}
+
 func parseRecordRoute(data []byte) (IPOption, error) {
-	var rr RR
-	rr.option.otype = OptionType(data[0])
-	rr.option.length = int(data[1])
-	rr.option.data = make([]byte, rr.option.length, rr.option.length)
-	copy(rr.option.data, data)
+	l, err := parseAddressList(data)
+	if err != nil {
+		return nil, err
+	}
+	return RR{l}, nil
+}
+
+// LSRR is an ipv4 loose source and record route option
+type LSRR struct {
+	RR
+}
 
-	rr.Pointer = rr.option.data[2]
-	if (rr.option.length-3)%4 != 0 {
-		return nil, ErrIncorrectRRLength
+func parseLSRR(data []byte) (IPOption, error) {
+	rr, err := parseRecordRoute(data)
+	if err != nil {
+		return nil, err
 	}
-	var i int
-	for i = 3; i < rr.option.length; i += 4 {
-		var route Route
-		route |= Route(rr.option.data[i]) << 24
-		route |= Route(rr.option.data[i+1]) << 16
-		route |= Route(rr.option.data[i+2]) << 8
-		route |= Route(rr.option.data[i+3])
+	return LSRR{rr.(RR)}, nil
+}
+
+// SSRR is an ipv4 strict source and record route option
+type SSRR struct {
+	RR
+}
+
+func parseSSRR(data []byte) (IPOption, error) {
+	rr, err := parseRecordRoute(data)
+	if err != nil {
+		return nil, err
+	}
+	return SSRR{rr.(RR)}, nil
+}
+
+// SDB is an ipv4 selective directed broadcast option (RFC 1770). Its Routes
+// are the directed broadcast addresses of the subnets the datagram is
+// delivered to, and its Pointer selects the next of them.
+type SDB struct {
+	AddressList
+}
+
+func parseSDB(data []byte) (IPOption, error) {
+	l, err := parseAddressList(data)
+	if err != nil {
+		return nil, err
+	}
+	return SDB{l}, nil
+}
+
+// UMP is an ipv4 upstream multicast packet option. It is carried as an
+// address list like the route options.
+type UMP struct {
+	AddressList
+}
 
-		rr.Routes = append(rr.Routes, route)
+func parseUMP(data []byte) (IPOption, error) {
+	l, err := parseAddressList(data)
+	if err != nil {
+		return nil, err
 	}
-	return rr, nil
+	return UMP{l}, nil
+}
+
+// UInt16Option is an option carrying a single 16-bit value, 4 bytes long in
+// total. It is the common form of StreamID, RouterAlert, MTUProbe and
+// MTUReply, and can be registered with RegisterParser for other options of
+// this form.
+type UInt16Option struct {
+	option
+	Value uint16
 }
 
-//StreamID is an ipv4 stream id option
+const uint16OptLen = 4
+
+// ParseUInt16Option parses the option at the start of data as a UInt16Option
+// of the type given by its first byte.
+func ParseUInt16Option(data []byte) (IPOption, error) {
+	return parseUInt16(data)
+}
+
+func parseUInt16(data []byte) (UInt16Option, error) {
+	var u UInt16Option
+	if len(data) < uint16OptLen {
+		return u, ErrTruncatedOption
+	}
+	u.option.otype = OptionType(data[0])
+	u.option.length = uint16OptLen
+	u.option.data = make([]byte, uint16OptLen, uint16OptLen)
+	copy(u.option.data, data)
+	u.Value = binary.BigEndian.Uint16(data[2:])
+	return u, nil
+}
+
+// NewUInt16Option returns an option of type t carrying v.
+func NewUInt16Option(t OptionType, v uint16) UInt16Option {
+	u := UInt16Option{Value: v}
+	u.option.otype = t
+	u.option.length = uint16OptLen
+	u.option.data = appendUInt16(nil, t, v)
+	return u
+}
+
+// appendUInt16 appends the wire format of an option of type t carrying v to
+// dst.
+func appendUInt16(dst []byte, t OptionType, v uint16) []byte {
+	return append(dst, byte(t), uint16OptLen, byte(v>>8), byte(v))
+}
+
+//StreamID is an ipv4 stream id option. It was deprecated by RFC 6814, as
+// LookupOptionInfo reports, but is still decoded for old captures.
 type StreamID struct {
 	option
+	// ID is the stream id with any padding before it removed.
 	ID uint16
+	// Raw is the stream id as carried in the option, including any
+	// padding some implementations place before it.
+	Raw []byte
 }
 
-const streamIDOptLen = 4
+const streamIDOptLen = uint16OptLen
 
 func parseStreamID(data []byte) (IPOption, error) {
-	var sid StreamID
-	sid.option.otype = OptionType(data[0])
-	sid.option.length = streamIDOptLen
-	sid.option.data = make([]byte, streamIDOptLen, streamIDOptLen)
-	copy(sid.option.data, data)
-	if len(data) < 4 {
-		return nil, fmt.Errorf("Not enought data for stream id option")
+	u, err := parseUInt16(data)
+	if err != nil {
+		return nil, err
 	}
-	sid.ID |= uint16(data[2]) << 8
-	sid.ID |= uint16(data[3])
-
+	sid := StreamID{option: u.option, ID: u.Value}
+	if int(data[1]) > streamIDOptLen && int(data[1]) <= len(data) {
+		sid.option.length = int(data[1])
+		sid.option.data = make([]byte, sid.option.length, sid.option.length)
+		copy(sid.option.data, data)
+	}
+	sid.Raw = sid.option.data[2:]
+	sid.ID = sid.Normalized()
 	return sid, nil
+}
+
+// Normalized returns the stream id with any leading padding in the option
+// removed. It is the ID of a parsed option.
+func (s StreamID) Normalized() uint16 {
+	if len(s.Raw) < 2 {
+		return s.ID
+	}
+	return uint16(s.Raw[len(s.Raw)-2])<<8 | uint16(s.Raw[len(s.Raw)-1])
+}
+
+// RouterAlert is the ipv4 router alert option
+type RouterAlert struct {
+	option
+	// Value is 0 when routers must examine the datagram, other values are
+	// reserved.
+	Value uint16
+}
+
+func parseRouterAlert(data []byte) (IPOption, error) {
+	u, err := parseUInt16(data)
+	if err != nil {
+		return nil, err
+	}
+	return RouterAlert{option: u.option, Value: u.Value}, nil
+}
+
+// MTUProbe is the ipv4 MTU probe option
+type MTUProbe struct {
+	option
+	MTU uint16
+}
+
+// MTUReply is the ipv4 MTU reply option
+type MTUReply struct {
+	option
+	MTU uint16
+}
+
+func parseMTUProbe(data []byte) (IPOption, error) {
+	u, err := parseUInt16(data)
+	if err != nil {
+		return nil, err
+	}
+	return MTUProbe{option: u.option, MTU: u.Value}, nil
+}
+
+func parseMTUReply(data []byte) (IPOption, error) {
+	u, err := parseUInt16(data)
+	if err != nil {
+		return nil, err
+	}
+	return MTUReply{option: u.option, MTU: u.Value}, nil
+}
+
+// Traceroute is the ipv4 traceroute option
+type Traceroute struct {
+	option
+	ID           uint16
+	OutboundHops uint16
+	ReturnHops   uint16
+	Originator   Address
+}
+
+const tracerouteOptLen = 12
 
+func parseTraceroute(data []byte) (IPOption, error) {
+	var tr Traceroute
+	if len(data) < tracerouteOptLen {
+		return nil, ErrTruncatedOption
+	}
+	tr.option.otype = TracerouteOption
+	tr.option.length = tracerouteOptLen
+	tr.option.data = make([]byte, tracerouteOptLen, tracerouteOptLen)
+	copy(tr.option.data, data)
+	tr.ID = binary.BigEndian.Uint16(data[2:])
+	tr.OutboundHops = binary.BigEndian.Uint16(data[4:])
+	tr.ReturnHops = binary.BigEndian.Uint16(data[6:])
+	tr.Originator = Address(binary.BigEndian.Uint32(data[8:]))
+
+	return tr, nil
+}
+
+const (
+	// QSRateRequest is the Quick-Start function for a rate request.
+	QSRateRequest = 0
+	// QSRateReport is the Quick-Start function for a rate report.
+	QSRateReport = 8
+)
+
+// QuickStart is the ipv4 quick-start option
+type QuickStart struct {
+	option
+	Function uint8
+	// Rate is the requested or reported rate, encoded as 40000 * 2^Rate
+	// bits per second.
+	Rate uint8
+	TTL  uint8
+	// Nonce is the 30 bit QS nonce.
+	Nonce uint32
+}
+
+const quickStartOptLen = 8
+
+func parseQuickStart(data []byte) (IPOption, error) {
+	var qs QuickStart
+	if len(data) < quickStartOptLen {
+		return nil, ErrTruncatedOption
+	}
+	qs.option.otype = QuickStartOption
+	qs.option.length = quickStartOptLen
+	qs.option.data = make([]byte, quickStartOptLen, quickStartOptLen)
+	copy(qs.option.data, data)
+	qs.Function = data[2] >> 4
+	qs.Rate = data[2] & 0x0F
+	qs.TTL = data[3]
+	qs.Nonce |= uint32(data[4]) << 22
+	qs.Nonce |= uint32(data[5]) << 14
+	qs.Nonce |= uint32(data[6]) << 6
+	qs.Nonce |= uint32(data[7]) >> 2
+
+	return qs, nil
 }
 
 //Stamp represents a timestamp address pair from a timestamp option
@@ -266,60 +656,81 @@ type TS struct {
 
 func parseTimeStamp(data []byte) (IPOption, error) {
 	var ts TS
+	if err := ts.decode(data); err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
 
+// decode sets ts to the timestamp option at the start of data, reusing the
+// storage left by Reset when it is large enough. ts is left unchanged on
+// error.
+func (ts *TS) decode(data []byte) error {
+	length, err := optionLength(data, 4)
+	if err != nil {
+		return err
+	}
+	flags := Flag(data[3] & 0x0F)
+	// Options with an undefined flag are kept without stamps so that they
+	// can be inspected; Validate reports the flag.
+	switch flags {
+	case TSOnly:
+		if (length-4)%4 != 0 {
+			return ErrBadTimestampLength
+		}
+	case TSAndAddr, TSPrespec:
+		if (length-4)%8 != 0 {
+			return ErrBadTimestampLength
+		}
+	}
 	ts.option.otype = OptionType(data[0])
-	ts.option.length = int(data[1])
-	ts.option.data = make([]byte, ts.option.length, ts.option.length)
-	copy(ts.option.data, data)
+	ts.option.length = length
+	ts.option.data = append(reuse(ts.option.data, length), data[:length]...)
 	ts.Pointer = data[2]
 	ts.Over = Overflow(data[3] >> 4)
-	ts.Flags = Flag(data[3] & 0x0F)
-	var err error
+	ts.Flags = flags
 	switch ts.Flags {
 	case TSOnly:
-		ts.Stamps, err = getStampsTSOnly(data[4:], ts.option.length-4)
-		if err != nil {
-			return nil, err
-		}
+		ts.Stamps = getStampsTSOnly(ts.Stamps, ts.option.data[4:], ts.option.length-4)
 	case TSAndAddr, TSPrespec:
-		ts.Stamps, err = getStamps(data[4:], ts.option.length-4)
-		if err != nil {
-			return nil, err
-		}
+		ts.Stamps = getStamps(ts.Stamps, ts.option.data[4:], ts.option.length-4)
+	default:
+		ts.Stamps = nil
 	}
-	return ts, nil
+	return nil
 }
 
-func getStampsTSOnly(data []byte, length int) ([]Stamp, error) {
-	var stamp []Stamp
-	var i int
-	for i = 0; i < length; i += 4 {
-		st := Stamp{}
-		st.Time |= Timestamp(data[i]) << 24
-		st.Time |= Timestamp(data[i+1]) << 16
-		st.Time |= Timestamp(data[i+2]) << 8
-		st.Time |= Timestamp(data[i+3])
-		stamp = append(stamp, st)
+// Reset clears the option but keeps the storage of its data and stamps, so
+// that a value kept in a sync.Pool can be filled again by UnmarshalBinary
+// without allocating. Copies of the option must no longer be used, as they
+// share that storage.
+func (ts *TS) Reset() {
+	*ts = TS{
+		option: option{data: ts.option.data[:0]},
+		Stamps: ts.Stamps[:0],
 	}
-	return stamp, nil
 }
 
-func getStamps(data []byte, length int) ([]Stamp, error) {
-	var stamp []Stamp
-	var i int
-	for i = 0; i < length; i += 8 {
-		st := Stamp{}
-		st.Addr |= Address(data[i]) << 24
-		st.Addr |= Address(data[i+1]) << 16
-		st.Addr |= Address(data[i+2]) << 8
-		st.Addr |= Address(data[i+3])
-		st.Time |= Timestamp(data[i+4]) << 24
-		st.Time |= Timestamp(data[i+5]) << 16
-		st.Time |= Timestamp(data[i+6]) << 8
-		st.Time |= Timestamp(data[i+7])
-		stamp = append(stamp, st)
+// getStampsTSOnly returns the stamps of TSOnly data in the storage of dst.
+func getStampsTSOnly(dst []Stamp, data []byte, length int) []Stamp {
+	stamp := reuse(dst, length/4)
+	for i := 0; i+4 <= length; i += 4 {
+		stamp = append(stamp, Stamp{Time: Timestamp(binary.BigEndian.Uint32(data[i:]))})
 	}
-	return stamp, nil
+	return stamp
+}
+
+// getStamps returns the stamps of TSAndAddr or TSPrespec data in the storage
+// of dst.
+func getStamps(dst []Stamp, data []byte, length int) []Stamp {
+	stamp := reuse(dst, length/8)
+	for i := 0; i+8 <= length; i += 8 {
+		stamp = append(stamp, Stamp{
+			Addr: Address(binary.BigEndian.Uint32(data[i:])),
+			Time: Timestamp(binary.BigEndian.Uint32(data[i+4:])),
+		})
+	}
+	return stamp
 }
 
 // NoOp is the NoOperation option
@@ -330,7 +741,7 @@ type NoOp struct {
 func parseNOOP(data []byte) (IPOption, error) {
 	var opt NoOp
 	if len(data) < 1 {
-		return nil, fmt.Errorf("Failed to parse NoOperation, no data available")
+		return nil, ErrTruncatedOption
 	}
 	opt.option.length = 1
 	opt.option.otype = NoOperation
@@ -347,78 +758,164 @@ type EOOList struct {
 func parseEOOList(data []byte) (IPOption, error) {
 	var opt EOOList
 	if len(data) < 1 {
-		return nil, fmt.Errorf("Failed to parse NoOperation, no data available")
+		return nil, ErrTruncatedOption
 	}
 	opt.option.length = 1
-	opt.option.otype = NoOperation
+	opt.option.otype = EndOfOptionList
 	opt.option.data = make([]byte, 1, 1)
 	copy(opt.option.data, data)
 	return opt, nil
 }
 
+// IsEOOL reports whether opt is an EndOfOptionList option.
+func IsEOOL(opt IPOption) bool {
+	if _, ok := opt.(EOOList); ok {
+		return true
+	}
+	return opt.Type() == EndOfOptionList
+}
+
+// IsNoOp reports whether opt is a NoOperation option.
+func IsNoOp(opt IPOption) bool {
+	if _, ok := opt.(NoOp); ok {
+		return true
+	}
+	return opt.Type() == NoOperation
+}
+
+// Experimental is an option of one of the RFC 4727 experimental types. Its
+// contents are defined by the experiment using it.
+type Experimental struct {
+	option
+	Value []byte
+}
+
+func parseExperimental(data []byte) (IPOption, error) {
+	var exp Experimental
+	length, err := optionLength(data, 2)
+	if err != nil {
+		return nil, err
+	}
+	exp.option.otype = OptionType(data[0])
+	exp.option.length = length
+	exp.option.data = make([]byte, exp.option.length, exp.option.length)
+	copy(exp.option.data, data)
+	exp.Value = exp.option.data[2:]
+	return exp, nil
+}
+
+// RawOption is an option of a type this package does not decode. Its type
+// and length are taken from the option and the rest is kept as is.
+type RawOption struct {
+	option
+	Value []byte
+}
+
+func parseRaw(data []byte) (IPOption, error) {
+	var raw RawOption
+	if len(data) < 2 || data[1] < 2 || int(data[1]) > len(data) {
+		return nil, ErrOptionType
+	}
+	raw.option.otype = OptionType(data[0])
+	raw.option.length = int(data[1])
+	raw.option.data = make([]byte, raw.option.length, raw.option.length)
+	copy(raw.option.data, data)
+	raw.Value = raw.option.data[2:]
+	return raw, nil
+}
+
 type parseFunc func([]byte) (IPOption, error)
 
+// optionLength returns the length field of the option at the start of data
+// after checking that it is at least min and that data holds the whole
+// option.
+func optionLength(data []byte, min int) (int, error) {
+	if len(data) < 2 {
+		return 0, ErrTruncatedOption
+	}
+	length := int(data[1])
+	if length < min {
+		return 0, ErrInvalidOptionLength
+	}
+	if length > len(data) {
+		return 0, ErrTruncatedOption
+	}
+	return length, nil
+}
+
+// reuse returns the storage of s, which was emptied by a Reset method, with
+// room for n elements. It allocates exactly n elements when s is too small
+// or still holds elements, which copies of the option may share.
+func reuse[T any](s []T, n int) []T {
+	if s == nil || len(s) > 0 || cap(s) < n {
+		return make([]T, 0, n)
+	}
+	return s
+}
+
+// parserFor returns the parser for options of type t, falling back to
+// RawOption for types without one.
+func parserFor(t OptionType) parseFunc {
+	if p, ok := lookupParser(t); ok {
+		return p
+	}
+	return parseRaw
+}
+
+// lookupParser returns the registered parser for options of type t.
+func lookupParser(t OptionType) (parseFunc, bool) {
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+	p, ok := parsers[t]
+	return p, ok
+}
+
+// parsersMu guards parsers, which RegisterParser may change while other
+// goroutines are parsing.
+var parsersMu sync.RWMutex
+
 var parsers = map[OptionType]parseFunc{
 	EndOfOptionList:         parseEOOList,
 	NoOperation:             parseNOOP,
-	Security:                parseSecurity,
-	LooseSourceRecordRoute:  parseRecordRoute,
-	StrictSourceRecordRoute: parseRecordRoute,
+	Security:                parseSecurityAuto,
+	LooseSourceRecordRoute:  parseLSRR,
+	StrictSourceRecordRoute: parseSSRR,
 	RecordRoute:             parseRecordRoute,
 	StreamIdentifier:        parseStreamID,
 	InternetTimestamp:       parseTimeStamp,
+	RouterAlertOption:       parseRouterAlert,
+	CommercialSecurity:      parseCIPSO,
+	ExtendedSecurity:        parseExtendedSecurity,
+	MTUProbeOption:          parseMTUProbe,
+	MTUReplyOption:          parseMTUReply,
+	TracerouteOption:        parseTraceroute,
+	QuickStartOption:        parseQuickStart,
+	ExperimentalOption30:    parseExperimental,
+	ExperimentalOption94:    parseExperimental,
+	ExperimentalOption158:   parseExperimental,
+	ExperimentalOption222:   parseExperimental,
+	// Historical options are known but carried as RawOption unless they have
+	// a structured type.
+	ZSUOption:              parseRaw,
+	EncodeOption:           parseRaw,
+	VISAOption:             parseRaw,
+	IMITDOption:            parseRaw,
+	EIPOption:              parseRaw,
+	AddressExtensionOption: parseRaw,
+	SDBOption:              parseSDB,
+	DPSOption:              parseRaw,
+	UMPOption:              parseUMP,
+	FINNOption:             parseRaw,
 }
 
 // Options is a list of IPv4 Options.
 type Options []IPOption
 
-//Parse parses opts into IPv4 options.
-func Parse(opts []byte) (Options, error) {
-	optsLen := len(opts)
-	var options Options
-	if optsLen > MaxOptionsLen {
-		return nil, ErrOptionDataTooLarge
-	}
-	if optsLen == 0 {
-		return options, nil
-	}
-	var i int
-	for i = 0; i < optsLen; {
-		oType, err := getOptionType(opts[i])
-		if err != nil {
-			return nil, err
-		}
-		o, err := parsers[oType](opts[i:])
-		if err != nil {
-			return nil, err
-		}
-		options = append(options, o)
-		i += o.Length()
-	}
-	return options, nil
-
-}
-
-func getOptionType(b byte) (OptionType, error) {
-	switch OptionType(b) {
-	case EndOfOptionList:
-		return EndOfOptionList, nil
-	case NoOperation:
-		return NoOperation, nil
-	case Security:
-		return Security, nil
-	case LooseSourceRecordRoute:
-		return LooseSourceRecordRoute, nil
-	case StrictSourceRecordRoute:
-		return StrictSourceRecordRoute, nil
-	case RecordRoute:
-		return RecordRoute, nil
-	case StreamIdentifier:
-		return StreamIdentifier, nil
-	case InternetTimestamp:
-		return InternetTimestamp, nil
-	default:
-		//Just return EndOfOptionList to satisfy return
-		return EndOfOptionList, ErrOptionType
+// Reset clears the options so the slice can be reused, for example from a
+// sync.Pool, with ParseInto without keeping the old options alive.
+func (o Options) Reset() Options {
+	for i := range o {
+		o[i] = nil
 	}
+	return o[:0]
 }
@@ -1,8 +1,12 @@
 package ipv4opt_test
 
 import (
+	"bytes"
+	"errors"
+	"net"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/rhansen2/ipv4optparser"
 )
@@ -245,3 +249,425 @@ func TestTimestamp(t *testing.T) {
 		}
 	}
 }
+
+func TestStreamIDNormalized(t *testing.T) {
+	for _, test := range []struct {
+		testData []byte
+		len      int
+		id       uint16
+	}{
+		{
+			testData: sidTest,
+			len:      4,
+			id:       0x1234,
+		},
+		{
+			testData: []byte{136, 6, 0, 0, 0x12, 0x34},
+			len:      6,
+			id:       0x1234,
+		},
+	} {
+		ops, err := ipv4opt.Parse(test.testData)
+		if err != nil {
+			t.Fatalf("Failed to parse test data: %v", err)
+		}
+		if len(ops) != 1 {
+			t.Fatalf("Wrong number of options, Expected(%v), Got(%v)", 1, len(ops))
+		}
+		sid := ops[0].(ipv4opt.StreamID)
+		if sid.Length() != test.len {
+			t.Fatalf("Incorrect option len, Expected(%v), Got(%v)", test.len, sid.Length())
+		}
+		if sid.Normalized() != test.id || sid.ID != test.id {
+			t.Fatalf("Wrong stream id, Expected(%v), Got(%v %v)", test.id, sid.Normalized(), sid.ID)
+		}
+		b, err := ops.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b[:test.len], test.testData) || len(b) != ops.WireLength() {
+			t.Fatalf("Wrong marshaled data, Expected(%v), Got(%v)", test.testData, b)
+		}
+		ops2, err := ipv4opt.Parse(b)
+		if err != nil {
+			t.Fatalf("Failed to parse marshaled data: %v", err)
+		}
+		if sid2 := ops2[0].(ipv4opt.StreamID); sid2.Normalized() != test.id || sid2.Length() != test.len {
+			t.Fatalf("Wrong round trip, Expected(%v %v), Got(%v %v)", test.id, test.len, sid2.Normalized(), sid2.Length())
+		}
+	}
+}
+
+func TestRouterAlert(t *testing.T) {
+	data := []byte{148, 4, 0, 0}
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	ra, ok := ops[0].(ipv4opt.RouterAlert)
+	if !ok {
+		t.Fatalf("Wrong option, Expected(RouterAlert), Got(%T)", ops[0])
+	}
+	if ra.Type() != ipv4opt.RouterAlertOption || ra.Length() != 4 || ra.Value != 0 {
+		t.Fatalf("Wrong router alert option, Got(%v)", ra)
+	}
+	b, err := ops.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(b, data) {
+		t.Fatalf("Wrong marshaled data, Expected(%v), Got(%v)", data, b)
+	}
+}
+
+func TestMTU(t *testing.T) {
+	data := []byte{11, 4, 0x05, 0xDC, 12, 4, 0x02, 0x40}
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	probe, ok := ops[0].(ipv4opt.MTUProbe)
+	if !ok || probe.Type() != ipv4opt.MTUProbeOption || probe.MTU != 1500 {
+		t.Fatalf("Wrong MTU probe option, Got(%v)", ops[0])
+	}
+	reply, ok := ops[1].(ipv4opt.MTUReply)
+	if !ok || reply.Type() != ipv4opt.MTUReplyOption || reply.MTU != 576 {
+		t.Fatalf("Wrong MTU reply option, Got(%v)", ops[1])
+	}
+	b, err := ops.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(b, data) {
+		t.Fatalf("Wrong marshaled data, Expected(%v), Got(%v)", data, b)
+	}
+}
+
+func TestTraceroute(t *testing.T) {
+	data := []byte{82, 12, 0x12, 0x34, 0, 3, 0xFF, 0xFF, 137, 165, 1, 25}
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	tr, ok := ops[0].(ipv4opt.Traceroute)
+	if !ok {
+		t.Fatalf("Wrong option, Expected(Traceroute), Got(%T)", ops[0])
+	}
+	expected := ipv4opt.Traceroute{
+		ID:           0x1234,
+		OutboundHops: 3,
+		ReturnHops:   0xFFFF,
+		Originator:   2309292313,
+	}
+	if tr.ID != expected.ID || tr.OutboundHops != expected.OutboundHops ||
+		tr.ReturnHops != expected.ReturnHops || tr.Originator != expected.Originator {
+		t.Fatalf("Wrong traceroute option, Expected(%v), Got(%v)", expected, tr)
+	}
+	b, err := tr.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(b, data) {
+		t.Fatalf("Wrong marshaled data, Expected(%v), Got(%v)", data, b)
+	}
+}
+
+func TestQuickStart(t *testing.T) {
+	data := []byte{25, 8, 0x05, 64, 0x12, 0x34, 0x56, 0x78}
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	qs, ok := ops[0].(ipv4opt.QuickStart)
+	if !ok {
+		t.Fatalf("Wrong option, Expected(QuickStart), Got(%T)", ops[0])
+	}
+	if qs.Function != ipv4opt.QSRateRequest || qs.Rate != 5 || qs.TTL != 64 || qs.Nonce != 0x12345678>>2 {
+		t.Fatalf("Wrong quick-start option, Got(%v)", qs)
+	}
+	b, err := qs.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(b, data) {
+		t.Fatalf("Wrong marshaled data, Expected(%v), Got(%v)", data, b)
+	}
+}
+
+func TestRawOption(t *testing.T) {
+	data := append([]byte{31, 4, 1, 2}, sidTest...)
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	if len(ops) != 2 {
+		t.Fatalf("Wrong number of options, Expected(%v), Got(%v)", 2, len(ops))
+	}
+	raw, ok := ops[0].(ipv4opt.RawOption)
+	if !ok {
+		t.Fatalf("Wrong option, Expected(RawOption), Got(%T)", ops[0])
+	}
+	if raw.Type() != 31 || raw.Length() != 4 || !reflect.DeepEqual(raw.Value, []byte{1, 2}) {
+		t.Fatalf("Wrong raw option, Got(%v)", raw)
+	}
+	if _, ok := ops[1].(ipv4opt.StreamID); !ok {
+		t.Fatalf("Wrong option, Expected(StreamID), Got(%T)", ops[1])
+	}
+	b, err := ops.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(b, data) {
+		t.Fatalf("Wrong marshaled data, Expected(%v), Got(%v)", data, b)
+	}
+	if _, err := ipv4opt.Parse([]byte{31, 10, 1, 2}); !errors.Is(err, ipv4opt.ErrOptionType) {
+		t.Fatalf("Wrong error, Expected(%v), Got(%v)", ipv4opt.ErrOptionType, err)
+	}
+}
+
+func TestParseLenient(t *testing.T) {
+	data := []byte{7, 10, 4, 0, 0, 0, 0, 0, 0, 0}
+	data = append(data, secTest...)
+	ops, errs := ipv4opt.ParseLenient(data)
+	if len(errs) != 1 || !errors.Is(errs[0], ipv4opt.ErrIncorrectRRLength) {
+		t.Fatalf("Wrong errors, Expected(%v), Got(%v)", []error{ipv4opt.ErrIncorrectRRLength}, errs)
+	}
+	if len(ops) != 1 {
+		t.Fatalf("Wrong number of options, Expected(%v), Got(%v)", 1, len(ops))
+	}
+	if _, ok := ops[0].(ipv4opt.Sec); !ok {
+		t.Fatalf("Wrong option, Expected(Sec), Got(%T)", ops[0])
+	}
+}
+
+func TestTruncatedOptions(t *testing.T) {
+	for _, test := range []struct {
+		testData []byte
+		err      error
+	}{
+		{testData: []byte{7}, err: ipv4opt.ErrTruncatedOption},
+		{testData: []byte{7, 0}, err: ipv4opt.ErrInvalidOptionLength},
+		{testData: []byte{7, 11, 4, 1, 2, 3, 4}, err: ipv4opt.ErrTruncatedOption},
+		{testData: []byte{68, 2}, err: ipv4opt.ErrInvalidOptionLength},
+		{testData: []byte{68, 12, 5, 0, 1, 2, 3, 4}, err: ipv4opt.ErrTruncatedOption},
+		{testData: []byte{136, 4, 0}, err: ipv4opt.ErrTruncatedOption},
+		{testData: []byte{130, 11, 0xD7, 0x88}, err: ipv4opt.ErrTruncatedOption},
+	} {
+		if _, err := ipv4opt.Parse(test.testData); !errors.Is(err, test.err) {
+			t.Fatalf("Wrong error for %v, Expected(%v), Got(%v)", test.testData, test.err, err)
+		}
+	}
+}
+
+func TestOptionError(t *testing.T) {
+	data := append(append([]byte{}, sidTest...), 7, 11, 4, 0)
+	_, err := ipv4opt.Parse(data)
+	var oerr *ipv4opt.OptionError
+	if !errors.As(err, &oerr) {
+		t.Fatalf("Wrong error, Expected(*OptionError), Got(%T)", err)
+	}
+	if oerr.Type != ipv4opt.RecordRoute || oerr.Offset != 4 || oerr.Err != ipv4opt.ErrTruncatedOption {
+		t.Fatalf("Wrong option error, Got(%+v)", oerr)
+	}
+}
+
+func TestInvalidOptionLength(t *testing.T) {
+	for _, testData := range [][]byte{
+		{136, 0, 0x12, 0x34},
+		{148, 1, 0, 0},
+		{68, 0, 0, 0},
+		{7, 43, 4, 0},
+		{130},
+	} {
+		_, err := ipv4opt.Parse(testData)
+		if !errors.Is(err, ipv4opt.ErrInvalidOptionLength) {
+			t.Fatalf("Wrong error for %v, Expected(%v), Got(%v)", testData, ipv4opt.ErrInvalidOptionLength, err)
+		}
+	}
+}
+
+func TestEntriesPresized(t *testing.T) {
+	for _, data := range [][]byte{rrTest, tsTest, tsTest2} {
+		ops, err := ipv4opt.Parse(data)
+		if err != nil {
+			t.Fatalf("Failed to parse test data: %v", err)
+		}
+		var l, c int
+		switch o := ops[0].(type) {
+		case ipv4opt.RR:
+			l, c = len(o.Routes), cap(o.Routes)
+		case ipv4opt.TS:
+			l, c = len(o.Stamps), cap(o.Stamps)
+		}
+		if l == 0 || l != c {
+			t.Fatalf("Entries not allocated at their exact size, Expected(%v), Got(%v)", l, c)
+		}
+	}
+}
+
+func TestOptionsImmutable(t *testing.T) {
+	rrOps, err := ipv4opt.Parse(rrTest)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	tsOps, err := ipv4opt.Parse(tsTest2)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	ops := ipv4opt.Options{rrOps[0], tsOps[0]}
+	rr, ts := ops[0].(ipv4opt.RR), ops[1].(ipv4opt.TS)
+	route, stamp := rr.Routes[0], ts.Stamps[0]
+	rr.Data()[3] = 0
+	if rr.DataUnsafe()[3] != rrTest[3] {
+		t.Fatalf("Data modified through Data(), Expected(%v), Got(%v)", rrTest[3], rr.DataUnsafe()[3])
+	}
+	rr.Pointer = 4
+	if err := rr.AppendRoute(net.IPv4(192, 0, 2, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if got := ops[0].(ipv4opt.RR).Routes[0]; got != route {
+		t.Fatalf("Shared option modified by AppendRoute, Expected(%v), Got(%v)", route, got)
+	}
+	ts.Pointer = 5
+	if err := ts.Stamp(net.IPv4(192, 0, 2, 1), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if got := ops[1].(ipv4opt.TS).Stamps[0]; got != stamp {
+		t.Fatalf("Shared option modified by Stamp, Expected(%v), Got(%v)", stamp, got)
+	}
+}
+
+func TestPaddingOnly(t *testing.T) {
+	tests := []struct {
+		data []byte
+		eool []bool
+	}{
+		{[]byte{1, 1, 1, 1}, []bool{false, false, false, false}},
+		{[]byte{1, 1, 1, 0}, []bool{false, false, false, true}},
+		{[]byte{0, 0, 0, 0}, []bool{true}},
+		{[]byte{1, 0, 0, 0}, []bool{false, true}},
+	}
+	for _, test := range tests {
+		ops, err := ipv4opt.Parse(test.data)
+		if err != nil {
+			t.Fatalf("Failed to parse test data: %v", err)
+		}
+		if len(ops) != len(test.eool) {
+			t.Fatalf("Wrong number of options, Expected(%v), Got(%v)", len(test.eool), len(ops))
+		}
+		for i, opt := range ops {
+			eool := test.eool[i]
+			if ipv4opt.IsEOOL(opt) != eool || ipv4opt.IsNoOp(opt) == eool {
+				t.Fatalf("Wrong padding kind for option %d of %v, Expected(EOOL=%v), Got(%T %v)", i, test.data, eool, opt, opt.Type())
+			}
+			want := ipv4opt.OptionType(ipv4opt.NoOperation)
+			if eool {
+				want = ipv4opt.EndOfOptionList
+			}
+			if opt.Type() != want {
+				t.Fatalf("Wrong type for option %d of %v, Expected(%v), Got(%v)", i, test.data, want, opt.Type())
+			}
+		}
+		b, err := ops.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(b, test.data) {
+			t.Fatalf("Wrong marshaled data, Expected(%v), Got(%v)", test.data, b)
+		}
+	}
+}
+
+func TestUInt16Option(t *testing.T) {
+	p := ipv4opt.NewParser(ipv4opt.WithParser(202, ipv4opt.ParseUInt16Option))
+	data := []byte{202, 4, 0x12, 0x34, 148, 4, 0, 1}
+	ops, err := p.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	u, ok := ops[0].(ipv4opt.UInt16Option)
+	if !ok {
+		t.Fatalf("Wrong option, Expected(UInt16Option), Got(%T)", ops[0])
+	}
+	if u.Type() != 202 || u.Value != 0x1234 {
+		t.Fatalf("Wrong option, Expected(%v), Got(%v)", data[:4], u)
+	}
+	if ra := ops[1].(ipv4opt.RouterAlert); ra.Value != 1 {
+		t.Fatalf("Wrong router alert value, Expected(%v), Got(%v)", 1, ra.Value)
+	}
+	built := ipv4opt.NewUInt16Option(202, 0x1234)
+	if !built.Equal(u) || !reflect.DeepEqual(built.Data(), data[:4]) {
+		t.Fatalf("Expected(%v), Got(%v)", data[:4], built.Data())
+	}
+	b, err := ops.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(b, data) {
+		t.Fatalf("Wrong marshaled data, Expected(%v), Got(%v)", data, b)
+	}
+	if _, err := ipv4opt.ParseUInt16Option([]byte{202, 4, 0}); !errors.Is(err, ipv4opt.ErrTruncatedOption) {
+		t.Fatalf("Wrong error, Expected(%v), Got(%v)", ipv4opt.ErrTruncatedOption, err)
+	}
+}
+
+func TestHistoricalOptions(t *testing.T) {
+	p := ipv4opt.NewParser(ipv4opt.WithUnknownOptionHandling(ipv4opt.UnknownError))
+	for _, test := range []struct {
+		otype  ipv4opt.OptionType
+		str    string
+		status ipv4opt.OptionStatus
+	}{
+		{ipv4opt.ZSUOption, "ZSU{0102}", ipv4opt.StatusCurrent},
+		{ipv4opt.EncodeOption, "ENCODE{0102}", ipv4opt.StatusCurrent},
+		{ipv4opt.VISAOption, "VISA{0102}", ipv4opt.StatusCurrent},
+		{ipv4opt.IMITDOption, "IMITD{0102}", ipv4opt.StatusCurrent},
+		{ipv4opt.EIPOption, "EIP{0102}", ipv4opt.StatusDeprecated},
+		{ipv4opt.AddressExtensionOption, "ADDEXT{0102}", ipv4opt.StatusDeprecated},
+		{ipv4opt.DPSOption, "DPS{0102}", ipv4opt.StatusDeprecated},
+		{ipv4opt.FINNOption, "FINN{0102}", ipv4opt.StatusCurrent},
+	} {
+		ops, err := p.Parse([]byte{byte(test.otype), 4, 1, 2})
+		if err != nil {
+			t.Fatalf("Failed to parse option %v: %v", test.otype, err)
+		}
+		if _, ok := ops[0].(ipv4opt.RawOption); !ok {
+			t.Fatalf("Wrong option, Expected(RawOption), Got(%T)", ops[0])
+		}
+		if ops.String() != test.str {
+			t.Fatalf("Wrong string, Expected(%v), Got(%v)", test.str, ops.String())
+		}
+		if info, ok := ipv4opt.LookupOptionInfo(test.otype); !ok || info.Status != test.status {
+			t.Fatalf("Wrong registry entry for %v, Got(%+v)", test.otype, info)
+		}
+	}
+}
+
+func TestPayload(t *testing.T) {
+	data := []byte{1, 148, 4, 0, 0, 200, 4, 1, 2, 0}
+	ops, err := ipv4opt.NewParser(ipv4opt.WithParser(200, parseTestOption)).Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	expected := [][]byte{nil, {0, 0}, {1, 2}, nil}
+	for i, opt := range ops {
+		if p := opt.Payload(); !reflect.DeepEqual(p, expected[i]) {
+			t.Fatalf("Option %d, Wrong payload, Expected(%v), Got(%v)", i, expected[i], p)
+		}
+	}
+	ra := ops[1].(ipv4opt.RouterAlert)
+	ra.Payload()[0] = 0xff
+	if !reflect.DeepEqual(ra.Payload(), []byte{0, 0}) {
+		t.Fatalf("Payload is not a copy, Got(%v)", ra.Payload())
+	}
+	if !reflect.DeepEqual(ra.Data(), data[1:5]) {
+		t.Fatalf("Wrong data, Expected(%v), Got(%v)", data[1:5], ra.Data())
+	}
+	exp, err := ipv4opt.NewExperimental(ipv4opt.ExperimentalOption30, []byte{9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(exp.Payload(), []byte{9}) {
+		t.Fatalf("Wrong payload, Expected(%v), Got(%v)", []byte{9}, exp.Payload())
+	}
+}
@@ -0,0 +1,154 @@
+package ipv4opt
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	// headerLen is the length of an IPv4 header without options.
+	headerLen = 20
+	// etherHeaderLen is the length of an Ethernet II header.
+	etherHeaderLen = 14
+	// vlanTagLen is the length of an 802.1Q or 802.1ad tag.
+	vlanTagLen = 4
+	// icmpHeaderLen is the length of the ICMP header that precedes the
+	// datagram quoted by an ICMP error message.
+	icmpHeaderLen = 8
+)
+
+// EtherTypes of the Ethernet frames ParseTcpdump accepts.
+const (
+	etherTypeIPv4 = 0x0800
+	etherTypeVLAN = 0x8100
+	etherTypeQinQ = 0x88a8
+)
+
+// ICMP error message types that quote the header of the offending datagram.
+const (
+	icmpDestUnreachable  = 3
+	icmpSourceQuench     = 4
+	icmpRedirect         = 5
+	icmpTimeExceeded     = 11
+	icmpParameterProblem = 12
+)
+
+var (
+	// ErrShortPacket is returned when a packet is too short to hold the
+	// IPv4 header it describes.
+	ErrShortPacket = fmt.Errorf("The packet is too short for its IPv4 header")
+	// ErrNotIPv4 is returned when a packet does not have an IP version of 4.
+	ErrNotIPv4 = fmt.Errorf("The packet is not an IPv4 packet")
+	// ErrBadIHL is returned when the IHL field of a header is smaller than
+	// the minimum header length.
+	ErrBadIHL = fmt.Errorf("The IHL field is smaller than the minimum header length")
+	// ErrNotICMPError is returned when an ICMP message is not an error
+	// message quoting the datagram that caused it.
+	ErrNotICMPError = fmt.Errorf("The ICMP message is not an error message")
+	// ErrTcpdumpFormat is returned when no packet bytes can be found in
+	// tcpdump output.
+	ErrTcpdumpFormat = fmt.Errorf("No hex packet data found in tcpdump output")
+)
+
+// optionsRegion returns the options bytes of the IPv4 packet pkt as described
+// by its IHL field.
+func optionsRegion(pkt []byte) ([]byte, error) {
+	if len(pkt) < headerLen {
+		return nil, ErrShortPacket
+	}
+	if pkt[0]>>4 != 4 {
+		return nil, ErrNotIPv4
+	}
+	ihl := int(pkt[0]&0x0f) * 4
+	if ihl < headerLen {
+		return nil, ErrBadIHL
+	}
+	if ihl > len(pkt) {
+		return nil, ErrShortPacket
+	}
+	return pkt[headerLen:ihl], nil
+}
+
+// ParseFromPacket parses the options of the IPv4 packet pkt. The version
+// must be 4 and the options are taken from bytes 20 up to the header length
+// given by the IHL field.
+func ParseFromPacket(pkt []byte) (Options, error) {
+	opts, err := optionsRegion(pkt)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(opts)
+}
+
+// ParseFromICMPPayload parses the options of the IPv4 header quoted in an
+// ICMP error message, such as time exceeded or destination unreachable.
+// payload is the ICMP message, starting at its type byte. The quoted header
+// follows the 8 byte ICMP header and its IHL field gives the length of its
+// options, which must have been quoted in full.
+func ParseFromICMPPayload(payload []byte) (Options, error) {
+	if len(payload) < icmpHeaderLen {
+		return nil, ErrShortPacket
+	}
+	switch payload[0] {
+	case icmpDestUnreachable, icmpSourceQuench, icmpRedirect, icmpTimeExceeded, icmpParameterProblem:
+	default:
+		return nil, fmt.Errorf("%w: type %d", ErrNotICMPError, payload[0])
+	}
+	return ParseFromPacket(payload[icmpHeaderLen:])
+}
+
+// ParseTcpdump parses the IPv4 options of a packet printed by tcpdump with
+// the -x or -xx flags. Lines that do not start with an offset such as
+// "0x0000:" are ignored, as is the Ethernet header printed by -xx, which is
+// recognized by an IPv4 EtherType following any 802.1Q or 802.1ad tags.
+func ParseTcpdump(s string) (Options, error) {
+	var pkt []byte
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "0x") {
+			continue
+		}
+		colon := strings.IndexByte(line, ':')
+		if colon < 0 {
+			continue
+		}
+		for _, field := range strings.Fields(line[colon+1:]) {
+			if len(field) > 4 || len(field)%2 != 0 {
+				break
+			}
+			b, err := hex.DecodeString(field)
+			if err != nil {
+				break
+			}
+			pkt = append(pkt, b...)
+		}
+	}
+	if len(pkt) == 0 {
+		return nil, ErrTcpdumpFormat
+	}
+	if payload, ok := etherPayload(pkt); ok {
+		pkt = payload
+	}
+	return ParseFromPacket(pkt)
+}
+
+// etherPayload returns the IPv4 packet carried by the Ethernet frame pkt,
+// skipping any 802.1Q or 802.1ad tags. It reports false when pkt is not an
+// Ethernet frame carrying IPv4.
+func etherPayload(pkt []byte) ([]byte, bool) {
+	for i := etherHeaderLen - 2; i+2 < len(pkt); i += vlanTagLen {
+		switch binary.BigEndian.Uint16(pkt[i:]) {
+		case etherTypeVLAN, etherTypeQinQ:
+			continue
+		case etherTypeIPv4:
+			if pkt[i+2]>>4 != 4 {
+				return nil, false
+			}
+			return pkt[i+2:], true
+		}
+		return nil, false
+	}
+	return nil, false
+}
@@ -0,0 +1,121 @@
+package ipv4opt_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func TestParseTcpdump(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		dump string
+	}{
+		{
+			name: "ip",
+			dump: `12:00:00.000000 IP 192.168.0.1 > 192.168.0.2: ICMP echo request, id 1, seq 1, length 8
+	0x0000:  4600 0020 0001 0000 4001 0000 c0a8 0001
+	0x0010:  c0a8 0002 8804 1234 0800 f7fe 0001 0000
+`,
+		},
+		{
+			name: "link",
+			dump: `12:00:00.000000 IP 192.168.0.1 > 192.168.0.2: ICMP echo request, id 1, seq 1, length 8
+	0x0000:  0011 2233 4455 6677 8899 aabb 0800 4600
+	0x0010:  0020 0001 0000 4001 0000 c0a8 0001 c0a8
+	0x0020:  0002 8804 1234 0800 f7fe 0001 0000
+`,
+		},
+		{
+			name: "vlan",
+			dump: `12:00:00.000000 IP 192.168.0.1 > 192.168.0.2: ICMP echo request, id 1, seq 1, length 8
+	0x0000:  0011 2233 4455 6677 8899 aabb 8100 0064
+	0x0010:  0800 4600 0020 0001 0000 4001 0000 c0a8
+	0x0020:  0001 c0a8 0002 8804 1234 0800 f7fe 0001
+	0x0030:  0000
+`,
+		},
+		{
+			name: "mac like ipv4",
+			dump: `12:00:00.000000 IP 192.168.0.1 > 192.168.0.2: ICMP echo request, id 1, seq 1, length 8
+	0x0000:  4611 2233 4455 6677 8899 aabb 0800 4600
+	0x0010:  0020 0001 0000 4001 0000 c0a8 0001 c0a8
+	0x0020:  0002 8804 1234 0800 f7fe 0001 0000
+`,
+		},
+	} {
+		ops, err := ipv4opt.ParseTcpdump(test.dump)
+		if err != nil {
+			t.Fatalf("%v: Failed to parse tcpdump output: %v", test.name, err)
+		}
+		if len(ops) != 1 {
+			t.Fatalf("%v: Wrong number of options, Expected(%v), Got(%v)", test.name, 1, len(ops))
+		}
+		sid, ok := ops[0].(ipv4opt.StreamID)
+		if !ok {
+			t.Fatalf("%v: Wrong option, Expected(StreamID), Got(%T)", test.name, ops[0])
+		}
+		if sid.ID != 0x1234 {
+			t.Fatalf("%v: Wrong stream id, Expected(%v), Got(%v)", test.name, 0x1234, sid.ID)
+		}
+	}
+}
+
+func TestParseFromPacket(t *testing.T) {
+	tests := []struct {
+		pkt   []byte
+		types []ipv4opt.OptionType
+		err   error
+	}{
+		{headerTest, []ipv4opt.OptionType{ipv4opt.StreamIdentifier}, nil},
+		{headerTest[:10], nil, ipv4opt.ErrShortPacket},
+		{headerTest[:22], nil, ipv4opt.ErrShortPacket},
+		{append([]byte{0x45}, headerTest[1:20]...), nil, nil},
+		{append([]byte{0x66}, headerTest[1:]...), nil, ipv4opt.ErrNotIPv4},
+		{append([]byte{0x44}, headerTest[1:]...), nil, ipv4opt.ErrBadIHL},
+	}
+	for i, test := range tests {
+		ops, err := ipv4opt.ParseFromPacket(test.pkt)
+		if !errors.Is(err, test.err) {
+			t.Fatalf("Test %d, Expected(%v), Got(%v)", i, test.err, err)
+		}
+		if len(ops) != len(test.types) {
+			t.Fatalf("Test %d, Expected(%v), Got(%v)", i, test.types, ops)
+		}
+		for j, opt := range ops {
+			if opt.Type() != test.types[j] {
+				t.Fatalf("Test %d, Expected(%v), Got(%v)", i, test.types[j], opt.Type())
+			}
+		}
+	}
+}
+
+func TestParseFromICMPPayload(t *testing.T) {
+	timeExceeded := append([]byte{11, 0, 0xf4, 0xff, 0, 0, 0, 0}, headerTest...)
+	tests := []struct {
+		payload []byte
+		types   []ipv4opt.OptionType
+		err     error
+	}{
+		{timeExceeded, []ipv4opt.OptionType{ipv4opt.StreamIdentifier}, nil},
+		{append([]byte{3, 1, 0, 0, 0, 0, 0, 0}, headerTest[:24]...), []ipv4opt.OptionType{ipv4opt.StreamIdentifier}, nil},
+		{append([]byte{0, 0, 0, 0, 0, 1, 0, 1}, headerTest...), nil, ipv4opt.ErrNotICMPError},
+		{timeExceeded[:6], nil, ipv4opt.ErrShortPacket},
+		{timeExceeded[:30], nil, ipv4opt.ErrShortPacket},
+	}
+	for i, test := range tests {
+		ops, err := ipv4opt.ParseFromICMPPayload(test.payload)
+		if !errors.Is(err, test.err) {
+			t.Fatalf("Test %d, Expected(%v), Got(%v)", i, test.err, err)
+		}
+		if len(ops) != len(test.types) {
+			t.Fatalf("Test %d, Expected(%v), Got(%v)", i, test.types, ops)
+		}
+		for j, opt := range ops {
+			if opt.Type() != test.types[j] {
+				t.Fatalf("Test %d, Expected(%v), Got(%v)", i, test.types[j], opt.Type())
+			}
+		}
+	}
+}
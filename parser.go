@@ -0,0 +1,338 @@
+package ipv4opt
+
+import "fmt"
+
+var (
+	// ErrTooManyOptions is returned when a parser's limit on the number of
+	// options is exceeded.
+	ErrTooManyOptions = fmt.Errorf("Too many options")
+	// ErrNonZeroPadding is returned by parsers with strict padding when a
+	// byte after the end of option list is not zero.
+	ErrNonZeroPadding = fmt.Errorf("Non-zero byte after end of option list")
+)
+
+// UnknownOptionHandling selects what a Parser does with options of types it
+// does not decode.
+type UnknownOptionHandling int
+
+const (
+	// UnknownAsRaw returns unknown options as RawOption.
+	UnknownAsRaw UnknownOptionHandling = iota
+	// UnknownError fails parsing with ErrOptionType.
+	UnknownError
+	// UnknownSkip leaves unknown options out of the parsed list.
+	UnknownSkip
+)
+
+// Parser parses IPv4 options. The zero value parses like the package-level
+// Parse function, the same as NewParser with no options.
+type Parser struct {
+	strictLengths bool
+	unknown       UnknownOptionHandling
+	maxOptions    int
+	maxNOPRun     int
+	strictPadding bool
+	secFormat     SecurityFormat
+	parsers       map[OptionType]parseFunc
+	stats         *Stats
+}
+
+// ParserOption configures a Parser.
+type ParserOption func(*Parser)
+
+// WithStrictLengths requires the length field of every option to match the
+// number of bytes its parser consumed, as ParseStrict does.
+func WithStrictLengths() ParserOption {
+	return func(p *Parser) {
+		p.strictLengths = true
+	}
+}
+
+// WithUnknownOptionHandling sets how options of unknown types are handled.
+// The default is UnknownAsRaw.
+func WithUnknownOptionHandling(mode UnknownOptionHandling) ParserOption {
+	return func(p *Parser) {
+		p.unknown = mode
+	}
+}
+
+// WithMaxOptions limits the number of options, including padding, that may
+// be parsed. A limit of 0 means no limit.
+func WithMaxOptions(n int) ParserOption {
+	return func(p *Parser) {
+		p.maxOptions = n
+	}
+}
+
+// WithMaxNOPRun limits the number of consecutive NoOperation options that
+// may be parsed. Longer runs fail with ErrTooManyOptions. A limit of 0 means
+// no limit.
+func WithMaxNOPRun(n int) ParserOption {
+	return func(p *Parser) {
+		p.maxNOPRun = n
+	}
+}
+
+// WithStrictPadding requires every byte after an EndOfOptionList option to be
+// zero, as RFC 791 requires of the padding at the end of the header. Parsing
+// fails with ErrNonZeroPadding otherwise.
+func WithStrictPadding() ParserOption {
+	return func(p *Parser) {
+		p.strictPadding = true
+	}
+}
+
+// WithSecurityFormat sets the format security options are decoded in. The
+// default is SecurityAuto.
+func WithSecurityFormat(f SecurityFormat) ParserOption {
+	return func(p *Parser) {
+		p.secFormat = f
+	}
+}
+
+// WithParser decodes options of type t with f instead of the package's
+// parser for t.
+func WithParser(t OptionType, f func([]byte) (IPOption, error)) ParserOption {
+	return func(p *Parser) {
+		if p.parsers == nil {
+			p.parsers = make(map[OptionType]parseFunc)
+		}
+		p.parsers[t] = f
+	}
+}
+
+// RegisterParser registers f as the parser for options of type t for all
+// parsers, replacing any existing parser for t. f is given the options data
+// starting at the option and must return an option whose Length is the
+// number of bytes it consumed. RegisterParser is meant to be called during
+// initialization, but it is safe to call while other goroutines are parsing.
+func RegisterParser(t OptionType, f func([]byte) (IPOption, error)) {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+	parsers[t] = f
+}
+
+// NewParser returns a Parser configured with opts.
+func NewParser(opts ...ParserOption) *Parser {
+	p := &Parser{}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+var (
+	defaultParser = NewParser()
+	strictParser  = NewParser(WithStrictLengths())
+)
+
+// Parse parses opts into IPv4 options. Parsing stops at the first
+// EndOfOptionList option, which is included in the result; the bytes after
+// it are padding and are ignored.
+func Parse(opts []byte) (Options, error) {
+	return defaultParser.Parse(opts)
+}
+
+// ParseResult is the result of ParseDetailed.
+type ParseResult struct {
+	Options Options
+	// Padding holds the bytes after the EndOfOptionList option that ended
+	// the options, if any.
+	Padding []byte
+}
+
+// ParseDetailed parses opts like Parse and also returns the padding that
+// follows the end of the option list.
+func ParseDetailed(opts []byte) (ParseResult, error) {
+	return defaultParser.ParseDetailed(opts)
+}
+
+// ParseWithSecurityFormat parses opts like Parse, but decodes security
+// options in the format f.
+func ParseWithSecurityFormat(opts []byte, f SecurityFormat) (Options, error) {
+	return NewParser(WithSecurityFormat(f)).Parse(opts)
+}
+
+// ParseLenient parses opts like Parse, but does not stop at a malformed
+// option. The error for each malformed option is collected and parsing
+// continues after it if its length field allows it to be skipped.
+func ParseLenient(opts []byte) (Options, []error) {
+	return defaultParser.ParseLenient(opts)
+}
+
+// ParseInto parses opts like Parse and appends the options to dst. Passing
+// a slice from an earlier call, emptied with Reset, reuses its storage. On
+// error dst is returned unchanged.
+func ParseInto(opts []byte, dst Options) (Options, error) {
+	return defaultParser.ParseInto(opts, dst)
+}
+
+// Parse parses opts into IPv4 options. Parsing stops at the first
+// EndOfOptionList option.
+func (p *Parser) Parse(opts []byte) (Options, error) {
+	options, _, errs := p.parse(opts, nil, false)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return options, nil
+}
+
+// ParseDetailed parses opts like Parse and also returns the padding that
+// follows the end of the option list.
+func (p *Parser) ParseDetailed(opts []byte) (ParseResult, error) {
+	options, end, errs := p.parse(opts, nil, false)
+	if len(errs) > 0 {
+		return ParseResult{}, errs[0]
+	}
+	res := ParseResult{Options: options}
+	if end < len(opts) {
+		res.Padding = make([]byte, len(opts)-end)
+		copy(res.Padding, opts[end:])
+	}
+	return res, nil
+}
+
+// ParseInto parses opts like Parse and appends the options to dst. On error
+// dst is returned unchanged.
+func (p *Parser) ParseInto(opts []byte, dst Options) (Options, error) {
+	options, _, errs := p.parse(opts, dst, false)
+	if len(errs) > 0 {
+		return dst, errs[0]
+	}
+	return options, nil
+}
+
+// ParseLenient parses opts like Parse, but does not stop at a malformed
+// option. The error for each malformed option is collected and parsing
+// continues after it if its length field allows it to be skipped.
+func (p *Parser) ParseLenient(opts []byte) (Options, []error) {
+	options, _, errs := p.parse(opts, nil, true)
+	return options, errs
+}
+
+// checkPadding returns an OptionError wrapping ErrNonZeroPadding for the
+// first non-zero byte of opts from offset end if p has strict padding.
+func (p *Parser) checkPadding(opts []byte, end int) error {
+	if !p.strictPadding {
+		return nil
+	}
+	for i := end; i < len(opts); i++ {
+		if opts[i] != 0 {
+			return &OptionError{Type: OptionType(opts[i]), Offset: i, Err: ErrNonZeroPadding}
+		}
+	}
+	return nil
+}
+
+// parserFor returns the function used to parse the option at the start of
+// data and whether the option is of a known type.
+func (p *Parser) parserFor(data []byte) (parseFunc, bool) {
+	t := OptionType(data[0])
+	if f, ok := p.parsers[t]; ok {
+		return f, true
+	}
+	if t == Security {
+		return p.secFormat.parser(data), true
+	}
+	f, ok := lookupParser(t)
+	if !ok {
+		return parseRaw, false
+	}
+	return f, true
+}
+
+// parseAt parses the option at offset i of opts and reports whether it is of
+// a known type. Errors are wrapped in an OptionError.
+func (p *Parser) parseAt(opts []byte, i int) (IPOption, bool, error) {
+	f, known := p.parserFor(opts[i:])
+	var o IPOption
+	var err error
+	switch {
+	case !known && p.unknown == UnknownError:
+		err = ErrOptionType
+	case known && !isPaddingType(OptionType(opts[i])):
+		_, err = optionLength(opts[i:], 2)
+	}
+	if err == nil {
+		o, err = f(opts[i:])
+	}
+	if err == nil && (o.Length() < 1 || o.Length() > len(opts)-i) {
+		// Guard against parsers that would stall or overrun the cursor.
+		err = ErrInvalidOptionLength
+	}
+	if err == nil && p.strictLengths {
+		err = checkDeclaredLength(opts[i:], o)
+	}
+	if err != nil {
+		return nil, known, &OptionError{Type: OptionType(opts[i]), Offset: i, Err: err}
+	}
+	return o, known, nil
+}
+
+// limits tracks the options parsed so far against the limits of a Parser.
+type limits struct {
+	count  int
+	nopRun int
+}
+
+// add counts o, which was parsed at offset, and returns an OptionError
+// wrapping ErrTooManyOptions if a limit of p is exceeded.
+func (l *limits) add(p *Parser, o IPOption, offset int) error {
+	l.count++
+	if o.Type() == NoOperation {
+		l.nopRun++
+	} else {
+		l.nopRun = 0
+	}
+	if (p.maxOptions > 0 && l.count > p.maxOptions) || (p.maxNOPRun > 0 && l.nopRun > p.maxNOPRun) {
+		return &OptionError{Type: o.Type(), Offset: offset, Err: ErrTooManyOptions}
+	}
+	return nil
+}
+
+// parse parses opts and returns the options appended to dst, the offset at
+// which parsing stopped and the errors found.
+func (p *Parser) parse(opts []byte, dst Options, lenient bool) (Options, int, []error) {
+	optsLen := len(opts)
+	options := dst
+	var errs []error
+	if optsLen > MaxOptionsLen {
+		errs = []error{ErrOptionDataTooLarge}
+		if p.stats != nil {
+			p.stats.record(opts, nil, errs)
+		}
+		return dst, 0, errs
+	}
+	var l limits
+	i := 0
+	for i < optsLen {
+		o, known, err := p.parseAt(opts, i)
+		if err != nil {
+			errs = append(errs, err)
+			if !lenient || i+1 >= optsLen || opts[i+1] < 2 {
+				break
+			}
+			i += int(opts[i+1])
+			continue
+		}
+		i += o.Length()
+		if !known && p.unknown == UnknownSkip {
+			continue
+		}
+		if err := l.add(p, o, i-o.Length()); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		options = append(options, o)
+		if opts[i-o.Length()] == EndOfOptionList {
+			if err := p.checkPadding(opts, i); err != nil {
+				errs = append(errs, err)
+			}
+			break
+		}
+	}
+	if p.stats != nil {
+		p.stats.record(opts, options[len(dst):], errs)
+	}
+	return options, i, errs
+}
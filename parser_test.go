@@ -0,0 +1,206 @@
+package ipv4opt_test
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func TestParserOptions(t *testing.T) {
+	unknown := append([]byte{31, 4, 1, 2}, sidTest...)
+	for _, test := range []struct {
+		name     string
+		parser   *ipv4opt.Parser
+		testData []byte
+		count    int
+		err      error
+	}{
+		{
+			name:     "default",
+			parser:   ipv4opt.NewParser(),
+			testData: unknown,
+			count:    2,
+		},
+		{
+			name:     "zero value",
+			parser:   &ipv4opt.Parser{},
+			testData: unknown,
+			count:    2,
+		},
+		{
+			name:     "unknown error",
+			parser:   ipv4opt.NewParser(ipv4opt.WithUnknownOptionHandling(ipv4opt.UnknownError)),
+			testData: unknown,
+			err:      ipv4opt.ErrOptionType,
+		},
+		{
+			name:     "unknown skip",
+			parser:   ipv4opt.NewParser(ipv4opt.WithUnknownOptionHandling(ipv4opt.UnknownSkip)),
+			testData: unknown,
+			count:    1,
+		},
+		{
+			name:     "max options",
+			parser:   ipv4opt.NewParser(ipv4opt.WithMaxOptions(1)),
+			testData: unknown,
+			err:      ipv4opt.ErrTooManyOptions,
+		},
+		{
+			name:     "max NOP run",
+			parser:   ipv4opt.NewParser(ipv4opt.WithMaxNOPRun(3)),
+			testData: []byte{1, 1, 1, 1, 148, 4, 0, 0},
+			err:      ipv4opt.ErrTooManyOptions,
+		},
+		{
+			name:     "NOP run within limit",
+			parser:   ipv4opt.NewParser(ipv4opt.WithMaxNOPRun(3)),
+			testData: []byte{1, 1, 1, 148, 4, 0, 0, 1, 1, 1},
+			count:    7,
+		},
+		{
+			name:     "strict lengths",
+			parser:   ipv4opt.NewParser(ipv4opt.WithStrictLengths()),
+			testData: []byte{136, 3, 0, 1},
+			err:      ipv4opt.ErrUnexpectedNoOp,
+		},
+	} {
+		ops, err := test.parser.Parse(test.testData)
+		if !errors.Is(err, test.err) {
+			t.Fatalf("%v: Wrong error, Expected(%v), Got(%v)", test.name, test.err, err)
+		}
+		if len(ops) != test.count {
+			t.Fatalf("%v: Wrong number of options, Expected(%v), Got(%v)", test.name, test.count, len(ops))
+		}
+	}
+}
+
+func TestParseInto(t *testing.T) {
+	dst := make(ipv4opt.Options, 0, 8)
+	ops, err := ipv4opt.ParseInto(rrTest, dst)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	ops, err = ipv4opt.ParseInto(sidTest, ops)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	if len(ops) != 3 || ops[2].Type() != ipv4opt.StreamIdentifier {
+		t.Fatalf("Wrong options, Got(%v)", ops)
+	}
+	if &ops[0] != &dst[:1][0] {
+		t.Fatalf("Expected options to reuse dst")
+	}
+	ops = ops.Reset()
+	if len(ops) != 0 || cap(ops) != 8 {
+		t.Fatalf("Wrong reset options, Got(len %v, cap %v)", len(ops), cap(ops))
+	}
+	ops, err = ipv4opt.ParseInto([]byte{7}, ops)
+	if err == nil || len(ops) != 0 {
+		t.Fatalf("Expected error and unchanged dst, Got(%v, %v)", ops, err)
+	}
+}
+
+type testOption struct {
+	data []byte
+}
+
+func (o testOption) Type() ipv4opt.OptionType { return ipv4opt.OptionType(o.data[0]) }
+func (o testOption) Length() int              { return len(o.data) }
+func (o testOption) Data() []byte             { return o.data }
+func (o testOption) Payload() []byte          { return o.data[2:] }
+
+func parseTestOption(data []byte) (ipv4opt.IPOption, error) {
+	return testOption{data: data[:data[1]]}, nil
+}
+
+func TestRegisterParser(t *testing.T) {
+	data := []byte{200, 4, 1, 2, 31, 4, 1, 2}
+	ipv4opt.RestoreParsers(t)
+	ipv4opt.RegisterParser(200, parseTestOption)
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	if _, ok := ops[0].(testOption); !ok {
+		t.Fatalf("Wrong option, Expected(testOption), Got(%T)", ops[0])
+	}
+	if _, ok := ops[1].(ipv4opt.RawOption); !ok {
+		t.Fatalf("Wrong option, Expected(RawOption), Got(%T)", ops[1])
+	}
+	ops, err = ipv4opt.NewParser(ipv4opt.WithParser(31, parseTestOption)).Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	if _, ok := ops[1].(testOption); !ok {
+		t.Fatalf("Wrong option, Expected(testOption), Got(%T)", ops[1])
+	}
+}
+
+func TestRegisterParserConcurrent(t *testing.T) {
+	ipv4opt.RestoreParsers(t)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if _, err := ipv4opt.Parse([]byte{201, 4, 1, 2}); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	for j := 0; j < 100; j++ {
+		ipv4opt.RegisterParser(201, parseTestOption)
+	}
+	wg.Wait()
+}
+
+func TestParseStopsAtEOOL(t *testing.T) {
+	tests := []struct {
+		name    string
+		parser  *ipv4opt.Parser
+		data    []byte
+		count   int
+		padding []byte
+		err     error
+	}{
+		{"no EOOL", ipv4opt.NewParser(), []byte{148, 4, 0, 0}, 1, nil, nil},
+		{"zero padding", ipv4opt.NewParser(), []byte{148, 4, 0, 0, 0, 0, 0, 0}, 2, []byte{0, 0, 0}, nil},
+		{"options after EOOL", ipv4opt.NewParser(), []byte{0, 148, 4, 0}, 1, []byte{148, 4, 0}, nil},
+		{"malformed after EOOL", ipv4opt.NewParser(), []byte{1, 0, 7, 200, 4}, 2, []byte{7, 200, 4}, nil},
+		{"strict zero padding", ipv4opt.NewParser(ipv4opt.WithStrictPadding()), []byte{1, 0, 0, 0}, 2, []byte{0, 0}, nil},
+		{"strict non-zero padding", ipv4opt.NewParser(ipv4opt.WithStrictPadding()), []byte{1, 0, 0, 1}, 0, nil, ipv4opt.ErrNonZeroPadding},
+	}
+	for _, test := range tests {
+		res, err := test.parser.ParseDetailed(test.data)
+		if !errors.Is(err, test.err) {
+			t.Fatalf("%v: Wrong error, Expected(%v), Got(%v)", test.name, test.err, err)
+		}
+		if len(res.Options) != test.count {
+			t.Fatalf("%v: Wrong number of options, Expected(%v), Got(%v)", test.name, test.count, len(res.Options))
+		}
+		if !bytes.Equal(res.Padding, test.padding) {
+			t.Fatalf("%v: Wrong padding, Expected(%v), Got(%v)", test.name, test.padding, res.Padding)
+		}
+		var n int
+		var iterErr error
+		for _, err := range test.parser.Iterator(test.data).All() {
+			if err != nil {
+				iterErr = err
+				break
+			}
+			n++
+		}
+		if !errors.Is(iterErr, test.err) {
+			t.Fatalf("%v: Wrong iterator error, Expected(%v), Got(%v)", test.name, test.err, iterErr)
+		}
+		if test.err == nil && n != test.count {
+			t.Fatalf("%v: Wrong number of iterated options, Expected(%v), Got(%v)", test.name, test.count, n)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package ipv4opt
+
+import "fmt"
+
+var (
+	// ErrMultipleSourceRoutes is reported when a list holds more than one
+	// source route option.
+	ErrMultipleSourceRoutes = fmt.Errorf("More than one source route option")
+	// ErrRouterAlertNotFirst is reported when a router alert option follows
+	// an option other than padding.
+	ErrRouterAlertNotFirst = fmt.Errorf("Router alert follows other options")
+	// ErrOptionAfterEOOL is reported for options after an EndOfOptionList
+	// option.
+	ErrOptionAfterEOOL = fmt.Errorf("Option after end of option list")
+)
+
+// Violation is an option that breaks a rule of a Policy.
+type Violation struct {
+	// Index is the position of the option in the list.
+	Index int
+	Type  OptionType
+	Err   error
+}
+
+func (v Violation) Error() string {
+	return fmt.Sprintf("option %d (%v): %v", v.Index, v.Type, v.Err)
+}
+
+func (v Violation) Unwrap() error {
+	return v.Err
+}
+
+// Rule checks the structure of a list of options and returns the options
+// that break it.
+type Rule func(Options) []Violation
+
+// Policy is a set of rules a list of options must follow.
+type Policy []Rule
+
+// DefaultPolicy holds all the rules defined by the package.
+var DefaultPolicy = Policy{SingleSourceRoute, RouterAlertFirst, NoOptionsAfterEOOL}
+
+// CheckPolicy checks opts against every rule of p and returns the violations
+// found, in the order of the rules.
+func CheckPolicy(opts Options, p Policy) []Violation {
+	var vs []Violation
+	for _, rule := range p {
+		vs = append(vs, rule(opts)...)
+	}
+	return vs
+}
+
+// SingleSourceRoute allows at most one loose or strict source route option.
+// Every source route option after the first is a violation.
+func SingleSourceRoute(opts Options) []Violation {
+	var vs []Violation
+	seen := false
+	for i, opt := range opts {
+		if rr, ok := asRR(opt); !ok || !rr.IsSourceRoute() {
+			continue
+		}
+		if seen {
+			vs = append(vs, Violation{Index: i, Type: opt.Type(), Err: ErrMultipleSourceRoutes})
+		}
+		seen = true
+	}
+	return vs
+}
+
+// RouterAlertFirst requires router alert options to come before every other
+// option except padding, so that routers can find them without decoding the
+// rest of the list.
+func RouterAlertFirst(opts Options) []Violation {
+	var vs []Violation
+	others := false
+	for i, opt := range opts {
+		switch {
+		case opt.Type() == RouterAlertOption:
+			if others {
+				vs = append(vs, Violation{Index: i, Type: opt.Type(), Err: ErrRouterAlertNotFirst})
+			}
+		case !isPadding(opt):
+			others = true
+		}
+	}
+	return vs
+}
+
+// NoOptionsAfterEOOL forbids options after an EndOfOptionList option. Parsed
+// lists never break this rule, but built ones can.
+func NoOptionsAfterEOOL(opts Options) []Violation {
+	var vs []Violation
+	for i, opt := range opts {
+		if opt.Type() != EndOfOptionList {
+			continue
+		}
+		for j := i + 1; j < len(opts); j++ {
+			vs = append(vs, Violation{Index: j, Type: opts[j].Type(), Err: ErrOptionAfterEOOL})
+		}
+		break
+	}
+	return vs
+}
@@ -0,0 +1,59 @@
+package ipv4opt_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func mustParse(t *testing.T, parts ...[]byte) ipv4opt.Options {
+	t.Helper()
+	var ops ipv4opt.Options
+	for _, p := range parts {
+		o, err := ipv4opt.Parse(p)
+		if err != nil {
+			t.Fatalf("Failed to parse test data: %v", err)
+		}
+		ops = append(ops, o...)
+	}
+	return ops
+}
+
+func TestCheckPolicy(t *testing.T) {
+	ra := []byte{148, 4, 0, 0}
+	lsrr := []byte{131, 7, 4, 192, 0, 2, 1}
+	ssrr := []byte{137, 7, 4, 192, 0, 2, 1}
+	nop := []byte{1}
+	eool := []byte{0}
+	tests := []struct {
+		name     string
+		ops      ipv4opt.Options
+		expected []int
+		err      error
+	}{
+		{"clean", mustParse(t, ra, lsrr, eool), nil, nil},
+		{"padded router alert", mustParse(t, nop, ra, lsrr), nil, nil},
+		{"two source routes", mustParse(t, lsrr, ssrr), []int{1}, ipv4opt.ErrMultipleSourceRoutes},
+		{"late router alert", mustParse(t, lsrr, ra), []int{1}, ipv4opt.ErrRouterAlertNotFirst},
+		{"after eool", mustParse(t, eool, nop, nop), []int{1, 2}, ipv4opt.ErrOptionAfterEOOL},
+	}
+	for _, test := range tests {
+		vs := ipv4opt.CheckPolicy(test.ops, ipv4opt.DefaultPolicy)
+		var idx []int
+		for _, v := range vs {
+			if !errors.Is(v, test.err) {
+				t.Fatalf("%s: Wrong error, Expected(%v), Got(%v)", test.name, test.err, v)
+			}
+			idx = append(idx, v.Index)
+		}
+		if !reflect.DeepEqual(idx, test.expected) {
+			t.Fatalf("%s: Wrong violations, Expected(%v), Got(%v)", test.name, test.expected, idx)
+		}
+	}
+	vs := ipv4opt.CheckPolicy(mustParse(t, lsrr, ra), ipv4opt.Policy{ipv4opt.SingleSourceRoute})
+	if len(vs) != 0 {
+		t.Fatalf("Unexpected violations, Got(%v)", vs)
+	}
+}
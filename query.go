@@ -0,0 +1,75 @@
+package ipv4opt
+
+// RecordRoutes returns the record route style options in the list: record
+// route, loose source route and strict source route.
+func (o Options) RecordRoutes() []RR {
+	var rrs []RR
+	for _, opt := range o {
+		if rr, ok := asRR(opt); ok {
+			rrs = append(rrs, rr)
+		}
+	}
+	return rrs
+}
+
+// Timestamps returns the timestamp options in the list.
+func (o Options) Timestamps() []TS {
+	var tss []TS
+	for _, opt := range o {
+		if ts, ok := opt.(TS); ok {
+			tss = append(tss, ts)
+		}
+	}
+	return tss
+}
+
+// Security returns the first security option in the list, which is a Sec
+// for the RFC 791 format and a BasicSec for the RFC 1108 format.
+func (o Options) Security() (SecurityOption, bool) {
+	for _, opt := range o {
+		if sec, ok := opt.(SecurityOption); ok {
+			return sec, true
+		}
+	}
+	return nil, false
+}
+
+// Has reports whether the list holds an option of type t.
+func (o Options) Has(t OptionType) bool {
+	_, ok := o.Get(t)
+	return ok
+}
+
+// Get returns the first option of type t in the list.
+func (o Options) Get(t OptionType) (IPOption, bool) {
+	for _, opt := range o {
+		if opt.Type() == t {
+			return opt, true
+		}
+	}
+	return nil, false
+}
+
+// GetAll returns every option of type t in the list.
+func (o Options) GetAll(t OptionType) Options {
+	var all Options
+	for _, opt := range o {
+		if opt.Type() == t {
+			all = append(all, opt)
+		}
+	}
+	return all
+}
+
+// GetOption returns the first option in opts of the Go type T. The match is
+// on the Go type and not the option type, so GetOption[RR] does not return
+// source routes, which are LSRR and SSRR.
+func GetOption[T IPOption](opts Options) (T, bool) {
+	for _, opt := range opts {
+		if v, ok := opt.(T); ok {
+			return v, true
+		}
+	}
+	var zero T
+	return zero, false
+}
@@ -0,0 +1,102 @@
+package ipv4opt_test
+
+import (
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func TestTypedAccessors(t *testing.T) {
+	data := append(append([]byte{}, secTest...), tsPreSpec...)
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	if rrs := ops.RecordRoutes(); len(rrs) != 0 {
+		t.Fatalf("Wrong record routes, Expected(%v), Got(%v)", 0, len(rrs))
+	}
+	tss := ops.Timestamps()
+	if len(tss) != 1 || tss[0].Flags != ipv4opt.TSPrespec {
+		t.Fatalf("Wrong timestamps, Got(%v)", tss)
+	}
+	sec, ok := ops.Security()
+	if !ok {
+		t.Fatalf("Missing security option")
+	}
+	if level, _ := sec.SecurityLevel(); level != ipv4opt.Secret {
+		t.Fatalf("Wrong security option, Got(%v, %v)", sec, ok)
+	}
+
+	ops, err = ipv4opt.Parse(rrTest)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	if rrs := ops.RecordRoutes(); len(rrs) != 1 || rrs[0].Pointer != 40 {
+		t.Fatalf("Wrong record routes, Got(%v)", rrs)
+	}
+	if _, ok := ops.Security(); ok {
+		t.Fatalf("Unexpected security option")
+	}
+}
+
+func TestGet(t *testing.T) {
+	var data []byte
+	data = append(data, ipv4opt.NoOperation)
+	data = append(data, sidTest...)
+	data = append(data, 148, 4, 0, 0)
+	data = append(data, 148, 4, 0, 1)
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	tests := []struct {
+		otype ipv4opt.OptionType
+		count int
+	}{
+		{ipv4opt.NoOperation, 1},
+		{ipv4opt.StreamIdentifier, 1},
+		{ipv4opt.RouterAlertOption, 2},
+		{ipv4opt.InternetTimestamp, 0},
+	}
+	for _, test := range tests {
+		if ops.Has(test.otype) != (test.count > 0) {
+			t.Fatalf("Wrong presence of %v, Expected(%v), Got(%v)", test.otype, test.count > 0, ops.Has(test.otype))
+		}
+		opt, ok := ops.Get(test.otype)
+		if ok != (test.count > 0) || ok && opt.Type() != test.otype {
+			t.Fatalf("Wrong option for %v, Got(%v, %v)", test.otype, opt, ok)
+		}
+		if all := ops.GetAll(test.otype); len(all) != test.count {
+			t.Fatalf("Wrong count of %v, Expected(%v), Got(%v)", test.otype, test.count, len(all))
+		}
+	}
+	ra, _ := ops.Get(ipv4opt.RouterAlertOption)
+	if ra.(ipv4opt.RouterAlert).Value != 0 {
+		t.Fatalf("Expected the first router alert, Got(%v)", ra)
+	}
+}
+
+func TestGetOption(t *testing.T) {
+	data := append([]byte{148, 4, 0, 0, 148, 4, 0, 1, 131, 7, 4, 192, 0, 2, 1}, sidTest...)
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	ra, ok := ipv4opt.GetOption[ipv4opt.RouterAlert](ops)
+	if !ok || ra.Value != 0 {
+		t.Fatalf("Wrong router alert, Got(%v, %v)", ra, ok)
+	}
+	lsrr, ok := ipv4opt.GetOption[ipv4opt.LSRR](ops)
+	if !ok || len(lsrr.Routes) != 1 {
+		t.Fatalf("Wrong source route, Got(%v, %v)", lsrr, ok)
+	}
+	if _, ok := ipv4opt.GetOption[ipv4opt.RR](ops); ok {
+		t.Fatalf("Source route returned as record route")
+	}
+	if _, ok := ipv4opt.GetOption[ipv4opt.TS](ops); ok {
+		t.Fatalf("Unexpected timestamp option")
+	}
+	if _, ok := ipv4opt.GetOption[ipv4opt.StreamID](ops); !ok {
+		t.Fatalf("Missing stream identifier option")
+	}
+}
@@ -0,0 +1,293 @@
+package ipv4opt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+)
+
+// Exhausted reports whether every route slot in the option has been filled,
+// meaning the path may have been longer than the option could record. An
+// option with an invalid pointer is not exhausted.
+func (l AddressList) Exhausted() bool {
+	return int(l.Pointer) > l.Length() && l.checkPointer() == nil
+}
+
+// checkPointer returns an error wrapping ErrBadPointer unless the pointer is
+// at least 4, at most one past the end of the option and at the start of an
+// address.
+func (l AddressList) checkPointer() error {
+	if l.Pointer < 4 || int(l.Pointer) > l.Length()+1 || (l.Pointer-4)%4 != 0 {
+		return fmt.Errorf("%w: %d", ErrBadPointer, l.Pointer)
+	}
+	return nil
+}
+
+// recordedSlots returns the number of route slots before the pointer.
+func (l AddressList) recordedSlots() int {
+	if l.Pointer < 4 {
+		return 0
+	}
+	n := (int(l.Pointer) - 4) / 4
+	if n > len(l.Routes) {
+		return len(l.Routes)
+	}
+	return n
+}
+
+// Recorded returns the routes before the pointer, which have been filled in.
+// Slots at and after the pointer are unused. It returns nil if the pointer
+// is out of range or not at the start of an address.
+func (l AddressList) Recorded() []Route {
+	if l.checkPointer() != nil {
+		return nil
+	}
+	return l.Routes[:l.recordedSlots()]
+}
+
+// FreeSlots returns the number of route slots at and after the pointer that
+// have not been filled in yet. It returns 0 if the pointer is out of range or
+// not at the start of an address.
+func (l AddressList) FreeSlots() int {
+	if l.checkPointer() != nil {
+		return 0
+	}
+	return len(l.Routes) - l.recordedSlots()
+}
+
+// IsSourceRoute reports whether the option is a loose or strict source
+// route rather than a plain record route.
+func (rr RR) IsSourceRoute() bool {
+	return rr.Type() == LooseSourceRecordRoute || rr.Type() == StrictSourceRecordRoute
+}
+
+// PathTruncated reports whether any route option in the list is exhausted or
+// any timestamp option has overflowed, meaning the recorded path is
+// incomplete.
+func (o Options) PathTruncated() bool {
+	for _, opt := range o {
+		if rr, ok := asRR(opt); ok && rr.Exhausted() {
+			return true
+		}
+		if ts, ok := opt.(TS); ok && ts.Over > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encode appends the wire format of the option built from its fields to dst.
+func (l AddressList) encode(dst []byte) []byte {
+	t := l.otype
+	if t == 0 {
+		t = RecordRoute
+	}
+	length := 3 + 4*len(l.Routes)
+	b := slices.Grow(dst, length)
+	b = append(b, byte(t), byte(length), l.Pointer)
+	for _, r := range l.Routes {
+		b = binary.BigEndian.AppendUint32(b, uint32(r))
+	}
+	return b
+}
+
+// Recompute rebuilds the length and data of the option from its fields. It
+// must be called after the pointer or routes are changed.
+func (l *AddressList) Recompute() {
+	l.option.data = l.encode(nil)
+	l.option.otype = OptionType(l.option.data[0])
+	l.option.length = len(l.option.data)
+}
+
+// NewRecordRoute returns a record route option with slots empty route slots
+// and the pointer set to the first of them.
+func NewRecordRoute(slots int) (RR, error) {
+	if slots < 1 {
+		return RR{}, ErrInvalidOptionLength
+	}
+	if 3+4*slots > MaxOptionsLen {
+		return RR{}, ErrOptionDataTooLarge
+	}
+	rr := RR{AddressList{
+		Pointer: 4,
+		Routes:  make([]Route, slots),
+	}}
+	rr.option.otype = RecordRoute
+	rr.Recompute()
+	return rr, nil
+}
+
+// NewLSRR returns a loose source and record route option listing hops, with
+// the pointer set to the first of them.
+func NewLSRR(hops []net.IP) (LSRR, error) {
+	l, err := newAddressList(LooseSourceRecordRoute, hops)
+	return LSRR{RR{l}}, err
+}
+
+// NewSSRR returns a strict source and record route option listing hops, with
+// the pointer set to the first of them.
+func NewSSRR(hops []net.IP) (SSRR, error) {
+	l, err := newAddressList(StrictSourceRecordRoute, hops)
+	return SSRR{RR{l}}, err
+}
+
+// NewSDB returns a selective directed broadcast option listing the directed
+// broadcast addresses addrs, with the pointer set to the first of them.
+func NewSDB(addrs []net.IP) (SDB, error) {
+	l, err := newAddressList(SDBOption, addrs)
+	return SDB{l}, err
+}
+
+// NewUMP returns an upstream multicast packet option listing addrs, with the
+// pointer set to the first of them.
+func NewUMP(addrs []net.IP) (UMP, error) {
+	l, err := newAddressList(UMPOption, addrs)
+	return UMP{l}, err
+}
+
+// newAddressList returns an address list option of type t listing addrs,
+// with the pointer set to the first of them.
+func newAddressList(t OptionType, addrs []net.IP) (AddressList, error) {
+	if len(addrs) < 1 {
+		return AddressList{}, ErrInvalidOptionLength
+	}
+	if 3+4*len(addrs) > MaxOptionsLen {
+		return AddressList{}, ErrOptionDataTooLarge
+	}
+	l := AddressList{
+		Pointer: 4,
+		Routes:  make([]Route, len(addrs)),
+	}
+	for i, a := range addrs {
+		addr, err := AddressFromNetIP(a)
+		if err != nil {
+			return AddressList{}, err
+		}
+		l.Routes[i] = Route(addr)
+	}
+	l.option.otype = t
+	l.Recompute()
+	return l, nil
+}
+
+// slot returns the index of the route the pointer points at.
+func (l AddressList) slot() (int, error) {
+	if err := l.checkPointer(); err != nil {
+		return 0, err
+	}
+	i := (int(l.Pointer) - 4) / 4
+	if i >= len(l.Routes) {
+		return 0, ErrPathTruncated
+	}
+	return i, nil
+}
+
+// AppendRoute records ip in the slot at the pointer and advances the pointer
+// to the next slot, as a router does for a record route option. It returns
+// ErrPathTruncated if every slot has already been filled.
+func (rr *RR) AppendRoute(ip net.IP) error {
+	addr, err := AddressFromNetIP(ip)
+	if err != nil {
+		return err
+	}
+	i, err := rr.slot()
+	if err != nil {
+		return err
+	}
+	rr.Routes = slices.Clone(rr.Routes)
+	rr.Routes[i] = Route(addr)
+	rr.Pointer += 4
+	rr.Recompute()
+	return nil
+}
+
+// NextHop returns the address at the pointer of a source route, which is the
+// next hop once the datagram reaches its current destination. ok is false
+// when the route has been used up and the datagram is routed by its
+// destination address.
+func (rr RR) NextHop() (hop Route, ok bool) {
+	i, err := rr.slot()
+	if err != nil {
+		return 0, false
+	}
+	return rr.Routes[i], true
+}
+
+// Advance processes a source route at the host named by the destination
+// address, per RFC 791: the next hop at the pointer is returned to become
+// the new destination, it is replaced in the route by local, the address of
+// the outgoing interface, and the pointer is advanced. It returns
+// ErrPathTruncated when the route has been used up.
+func (rr *RR) Advance(local net.IP) (next Route, err error) {
+	if !rr.IsSourceRoute() {
+		return 0, fmt.Errorf("%w: %v is not a source route", ErrOptionType, rr.Type())
+	}
+	addr, err := AddressFromNetIP(local)
+	if err != nil {
+		return 0, err
+	}
+	i, err := rr.slot()
+	if err != nil {
+		return 0, err
+	}
+	next = rr.Routes[i]
+	rr.Routes = slices.Clone(rr.Routes)
+	rr.Routes[i] = Route(addr)
+	rr.Pointer += 4
+	rr.Recompute()
+	return next, nil
+}
+
+// Hops returns the recorded routes, those before the pointer, as addresses.
+// Like Recorded, it returns nil if the pointer is invalid.
+func (rr RR) Hops() []netip.Addr {
+	recorded := rr.Recorded()
+	if recorded == nil {
+		return nil
+	}
+	hops := make([]netip.Addr, len(recorded))
+	for i, r := range recorded {
+		hops[i] = r.Addr()
+	}
+	return hops
+}
+
+// Hop is a router on the path of a record route round trip, such as the echo
+// request and reply of ping -R. Forward is the address the router recorded
+// on the way to the destination and Return the address recorded at the same
+// distance from the source on the way back. Either is the zero Addr when
+// the paths differ in length or the option ran out of slots.
+type Hop struct {
+	Forward netip.Addr
+	Return  netip.Addr
+}
+
+// CorrelateRR pairs the forward and return hops of a record route round
+// trip. reply is the option of the reply as it came back to the source,
+// carrying the forward hops, the address recorded by the destination dst and
+// then the return hops. The hops are split at the first address equal to
+// dst, repeats of which are skipped, and the return hops are reversed so
+// that the hops are ordered by distance from the source. ok is false when
+// dst was not recorded, as happens when the option ran out of slots on the
+// way out or the destination recorded the address of another interface.
+func CorrelateRR(reply RR, dst netip.Addr) (hops []Hop, ok bool) {
+	all := reply.Hops()
+	n := slices.Index(all, dst)
+	if n < 0 {
+		return nil, false
+	}
+	forward, back := all[:n], all[n+1:]
+	for len(back) > 0 && back[0] == dst {
+		back = back[1:]
+	}
+	hops = make([]Hop, max(len(forward), len(back)))
+	for i, a := range forward {
+		hops[i].Forward = a
+	}
+	for i, a := range back {
+		hops[len(back)-1-i].Return = a
+	}
+	return hops, true
+}
@@ -0,0 +1,402 @@
+package ipv4opt_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+var rrEmptyTest = []byte{
+	7, 11, 4, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+}
+
+func TestPathTruncated(t *testing.T) {
+	for _, test := range []struct {
+		testData []byte
+		expected bool
+	}{
+		{
+			testData: rrTest,
+			expected: true,
+		},
+		{
+			testData: rrEmptyTest,
+			expected: false,
+		},
+	} {
+		ops, err := ipv4opt.Parse(test.testData)
+		if err != nil {
+			t.Fatalf("Failed to parse test data: %v", err)
+		}
+		rr := ops[0].(ipv4opt.RR)
+		if rr.Exhausted() != test.expected {
+			t.Fatalf("Wrong exhausted, Expected(%v), Got(%v)", test.expected, rr.Exhausted())
+		}
+		if ops.PathTruncated() != test.expected {
+			t.Fatalf("Wrong path truncated, Expected(%v), Got(%v)", test.expected, ops.PathTruncated())
+		}
+	}
+}
+
+func TestRRRecompute(t *testing.T) {
+	ops, err := ipv4opt.Parse(rrEmptyTest)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	rr := ops[0].(ipv4opt.RR)
+	before := rr.Length()
+	rr.Routes = append(rr.Routes, 169886669)
+	rr.Recompute()
+	if rr.Length() != before+4 {
+		t.Fatalf("Incorrect option len, Expected(%v), Got(%v)", before+4, rr.Length())
+	}
+	if len(rr.Data()) != rr.Length() || int(rr.Data()[1]) != rr.Length() {
+		t.Fatalf("Wrong data in option, Got(%v)", rr.Data())
+	}
+}
+
+func TestNewRecordRoute(t *testing.T) {
+	rr, err := ipv4opt.NewRecordRoute(9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ipv4opt.Options{rr}.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != ipv4opt.MaxOptionsLen {
+		t.Fatalf("Wrong marshaled length, Expected(%v), Got(%v)", ipv4opt.MaxOptionsLen, len(b))
+	}
+	ops, err := ipv4opt.Parse(b)
+	if err != nil {
+		t.Fatalf("Failed to parse marshaled data: %v", err)
+	}
+	parsed := ops[0].(ipv4opt.RR)
+	if parsed.Type() != ipv4opt.RecordRoute || parsed.Pointer != 4 || len(parsed.Routes) != 9 {
+		t.Fatalf("Wrong record route, Expected(%v), Got(%v)", rr, parsed)
+	}
+	if _, err := ipv4opt.NewRecordRoute(10); err != ipv4opt.ErrOptionDataTooLarge {
+		t.Fatalf("Wrong error, Expected(%v), Got(%v)", ipv4opt.ErrOptionDataTooLarge, err)
+	}
+}
+
+func TestNewSourceRoute(t *testing.T) {
+	hops := []net.IP{net.IPv4(10, 0, 0, 1), net.IPv4(10, 0, 0, 2)}
+	lsrr := func(hops []net.IP) (ipv4opt.IPOption, error) {
+		rr, err := ipv4opt.NewLSRR(hops)
+		return rr, err
+	}
+	ssrr := func(hops []net.IP) (ipv4opt.IPOption, error) {
+		rr, err := ipv4opt.NewSSRR(hops)
+		return rr, err
+	}
+	tests := []struct {
+		build    func([]net.IP) (ipv4opt.IPOption, error)
+		hops     []net.IP
+		expected []byte
+		err      error
+	}{
+		{lsrr, hops, []byte{131, 11, 4, 10, 0, 0, 1, 10, 0, 0, 2}, nil},
+		{ssrr, hops, []byte{137, 11, 4, 10, 0, 0, 1, 10, 0, 0, 2}, nil},
+		{lsrr, nil, nil, ipv4opt.ErrInvalidOptionLength},
+		{ssrr, make([]net.IP, 10), nil, ipv4opt.ErrOptionDataTooLarge},
+		{lsrr, []net.IP{net.IPv6loopback}, nil, ipv4opt.ErrBadAddress},
+	}
+	for _, test := range tests {
+		rr, err := test.build(test.hops)
+		if !errors.Is(err, test.err) {
+			t.Fatalf("Expected(%v), Got(%v)", test.err, err)
+		}
+		if err != nil {
+			continue
+		}
+		b, err := ipv4opt.Options{rr}.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b, test.expected) {
+			t.Fatalf("Expected(%v), Got(%v)", test.expected, b)
+		}
+	}
+}
+
+func TestSourceRouteTypes(t *testing.T) {
+	var data []byte
+	data = append(data, 131, 7, 4, 10, 0, 0, 1)
+	data = append(data, 137, 7, 4, 10, 0, 0, 2)
+	data = append(data, rrEmptyTest[:11]...)
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	lsrr, ok := ops[0].(ipv4opt.LSRR)
+	if !ok || !lsrr.IsSourceRoute() {
+		t.Fatalf("Expected a loose source route, Got(%T)", ops[0])
+	}
+	ssrr, ok := ops[1].(ipv4opt.SSRR)
+	if !ok || !ssrr.IsSourceRoute() {
+		t.Fatalf("Expected a strict source route, Got(%T)", ops[1])
+	}
+	rr, ok := ops[2].(ipv4opt.RR)
+	if !ok || rr.IsSourceRoute() {
+		t.Fatalf("Expected a record route, Got(%T)", ops[2])
+	}
+	if n := len(ops.RecordRoutes()); n != 3 {
+		t.Fatalf("Wrong number of routes, Expected(%v), Got(%v)", 3, n)
+	}
+}
+
+func TestRecordedRoutes(t *testing.T) {
+	tests := []struct {
+		data     []byte
+		recorded []ipv4opt.Route
+		free     int
+	}{
+		{rrEmptyTest[:11], []ipv4opt.Route{}, 2},
+		{[]byte{7, 11, 8, 10, 0, 0, 1, 0, 0, 0, 0}, []ipv4opt.Route{0x0a000001}, 1},
+		{[]byte{7, 11, 12, 10, 0, 0, 1, 10, 0, 0, 2}, []ipv4opt.Route{0x0a000001, 0x0a000002}, 0},
+		{[]byte{7, 7, 2, 10, 0, 0, 1}, nil, 0},
+		{[]byte{7, 7, 40, 10, 0, 0, 1}, nil, 0},
+		{[]byte{7, 11, 0, 10, 0, 0, 1, 0, 0, 0, 0}, nil, 0},
+		{[]byte{7, 11, 3, 10, 0, 0, 1, 0, 0, 0, 0}, nil, 0},
+		{[]byte{7, 11, 5, 10, 0, 0, 1, 0, 0, 0, 0}, nil, 0},
+		{[]byte{7, 11, 16, 10, 0, 0, 1, 10, 0, 0, 2}, nil, 0},
+	}
+	for _, test := range tests {
+		ops, err := ipv4opt.Parse(test.data)
+		if err != nil {
+			t.Fatalf("Failed to parse test data: %v", err)
+		}
+		rr := ops[0].(ipv4opt.RR)
+		if !reflect.DeepEqual(rr.Recorded(), test.recorded) {
+			t.Fatalf("Wrong recorded routes, Expected(%v), Got(%v)", test.recorded, rr.Recorded())
+		}
+		if rr.FreeSlots() != test.free {
+			t.Fatalf("Wrong free slots, Expected(%v), Got(%v)", test.free, rr.FreeSlots())
+		}
+		if hops := rr.Hops(); len(hops) != len(test.recorded) || (test.recorded == nil) != (hops == nil) {
+			t.Fatalf("Wrong hops, Expected(%v), Got(%v)", test.recorded, hops)
+		}
+	}
+}
+
+func TestAppendRoute(t *testing.T) {
+	rr, err := ipv4opt.NewRecordRoute(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ip := range []net.IP{net.IPv4(10, 0, 0, 1), net.IPv4(10, 0, 0, 2)} {
+		if err := rr.AppendRoute(ip); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rr.AppendRoute(net.IPv4(10, 0, 0, 3)); !errors.Is(err, ipv4opt.ErrPathTruncated) {
+		t.Fatalf("Expected(%v), Got(%v)", ipv4opt.ErrPathTruncated, err)
+	}
+	expected := []byte{7, 11, 12, 10, 0, 0, 1, 10, 0, 0, 2}
+	if !bytes.Equal(rr.Data(), expected) {
+		t.Fatalf("Expected(%v), Got(%v)", expected, rr.Data())
+	}
+}
+
+func TestSourceRouteAdvance(t *testing.T) {
+	lsrr, err := ipv4opt.NewLSRR([]net.IP{net.IPv4(10, 0, 0, 1), net.IPv4(10, 0, 0, 2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	locals := []net.IP{net.IPv4(192, 168, 0, 1), net.IPv4(192, 168, 1, 1)}
+	for i, local := range locals {
+		hop, ok := lsrr.NextHop()
+		if !ok {
+			t.Fatalf("Expected a next hop at step %d", i)
+		}
+		next, err := lsrr.Advance(local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next != hop || next != ipv4opt.Route(0x0a000001+i) {
+			t.Fatalf("Wrong next hop, Expected(%v), Got(%v)", hop, next)
+		}
+	}
+	if _, ok := lsrr.NextHop(); ok {
+		t.Fatalf("Expected the route to be used up")
+	}
+	if _, err := lsrr.Advance(locals[0]); !errors.Is(err, ipv4opt.ErrPathTruncated) {
+		t.Fatalf("Expected(%v), Got(%v)", ipv4opt.ErrPathTruncated, err)
+	}
+	expected := []byte{131, 11, 12, 192, 168, 0, 1, 192, 168, 1, 1}
+	if !bytes.Equal(lsrr.Data(), expected) {
+		t.Fatalf("Expected(%v), Got(%v)", expected, lsrr.Data())
+	}
+	rr, _ := ipv4opt.NewRecordRoute(1)
+	if _, err := rr.Advance(locals[0]); !errors.Is(err, ipv4opt.ErrOptionType) {
+		t.Fatalf("Expected(%v), Got(%v)", ipv4opt.ErrOptionType, err)
+	}
+}
+
+func TestSDB(t *testing.T) {
+	data := []byte{149, 11, 4, 192, 0, 2, 255, 198, 51, 100, 255}
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	sdb, ok := ops[0].(ipv4opt.SDB)
+	if !ok {
+		t.Fatalf("Wrong option, Expected(SDB), Got(%T)", ops[0])
+	}
+	if sdb.Pointer != 4 || len(sdb.Routes) != 2 || sdb.Routes[1].String() != "198.51.100.255" {
+		t.Fatalf("Wrong SDB, Got(%v)", sdb)
+	}
+	if sdb.String() != "SDB{ptr=4 192.0.2.255 198.51.100.255}" {
+		t.Fatalf("Wrong string, Expected(%v), Got(%v)", "SDB{ptr=4 192.0.2.255 198.51.100.255}", sdb.String())
+	}
+	built, err := ipv4opt.NewSDB([]net.IP{net.IPv4(192, 0, 2, 255), net.IPv4(198, 51, 100, 255)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !built.Equal(sdb) {
+		t.Fatalf("Expected(%v), Got(%v)", sdb, built)
+	}
+	b, err := built.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(b, data) {
+		t.Fatalf("Wrong marshaled data, Expected(%v), Got(%v)", data, b)
+	}
+	j, err := ops.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded ipv4opt.Options
+	if err := decoded.UnmarshalJSON(j); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := decoded[0].(ipv4opt.SDB); !ok || !decoded.Equal(ops) {
+		t.Fatalf("Wrong JSON round trip, Expected(%v), Got(%v)", ops, decoded)
+	}
+}
+
+func TestUMP(t *testing.T) {
+	data := []byte{152, 7, 4, 192, 0, 2, 1}
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	ump, ok := ops[0].(ipv4opt.UMP)
+	if !ok {
+		t.Fatalf("Wrong option, Expected(UMP), Got(%T)", ops[0])
+	}
+	if ump.Pointer != 4 || len(ump.Routes) != 1 || ump.Routes[0].String() != "192.0.2.1" {
+		t.Fatalf("Wrong UMP, Got(%v)", ump)
+	}
+	if ump.String() != "UMP{ptr=4 192.0.2.1}" {
+		t.Fatalf("Wrong string, Expected(%v), Got(%v)", "UMP{ptr=4 192.0.2.1}", ump.String())
+	}
+	built, err := ipv4opt.NewUMP([]net.IP{net.IPv4(192, 0, 2, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !built.Equal(ump) {
+		t.Fatalf("Expected(%v), Got(%v)", ump, built)
+	}
+	b, err := built.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(b, data) {
+		t.Fatalf("Wrong marshaled data, Expected(%v), Got(%v)", data, b)
+	}
+	j, err := ops.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded ipv4opt.Options
+	if err := decoded.UnmarshalJSON(j); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := decoded[0].(ipv4opt.UMP); !ok || !decoded.Equal(ops) {
+		t.Fatalf("Wrong JSON round trip, Expected(%v), Got(%v)", ops, decoded)
+	}
+	if _, err := ipv4opt.Parse([]byte{152, 6, 4, 192, 0, 2}); !errors.Is(err, ipv4opt.ErrIncorrectRRLength) {
+		t.Fatalf("Wrong error, Expected(%v), Got(%v)", ipv4opt.ErrIncorrectRRLength, err)
+	}
+}
+
+func TestCorrelateRR(t *testing.T) {
+	// The echo request sent by ping -R from 192.0.2.1 to 198.51.100.7 with
+	// an empty nine slot record route, and the echo reply with two forward
+	// hops, the destination and two return hops recorded.
+	request := "4f00004cbeef400040017a5ec0000201c6336407072704000000000000000000" +
+		"000000000000000000000000000000000000000000000000000000000800e5ca" +
+		"123400010000000000000000"
+	reply := "4f00004c0a0b40003d01dceec6336407c00002010727180a0000010a000101c6" +
+		"3364070a0001020a00000200000000000000000000000000000000000000edca" +
+		"123400010000000000000000"
+	var rrs []ipv4opt.RR
+	for _, s := range []string{request, reply} {
+		pkt, err := hex.DecodeString(s)
+		if err != nil {
+			t.Fatalf("Failed to decode test data: %v", err)
+		}
+		ops, err := ipv4opt.ParseFromPacket(pkt)
+		if err != nil {
+			t.Fatalf("Failed to parse test data: %v", err)
+		}
+		rrs = append(rrs, ops[0].(ipv4opt.RR))
+	}
+	dst := netip.MustParseAddr("198.51.100.7")
+	expected := []ipv4opt.Hop{
+		{Forward: netip.MustParseAddr("10.0.0.1"), Return: netip.MustParseAddr("10.0.0.2")},
+		{Forward: netip.MustParseAddr("10.0.1.1"), Return: netip.MustParseAddr("10.0.1.2")},
+	}
+	if got, ok := ipv4opt.CorrelateRR(rrs[1], dst); !ok || !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Wrong hops, Expected(%v), Got(%v, %v)", expected, got, ok)
+	}
+	if got, ok := ipv4opt.CorrelateRR(rrs[0], dst); ok || got != nil {
+		t.Fatalf("Correlated a request without recorded hops, Got(%v, %v)", got, ok)
+	}
+
+	// The destination recorded its address twice and the return path is
+	// one hop longer than the forward path.
+	data := []byte{7, 27, 24, 10, 0, 0, 1, 198, 51, 100, 7, 198, 51, 100, 7, 10, 0, 2, 2, 10, 0, 0, 2, 0, 0, 0, 0}
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	expected = []ipv4opt.Hop{
+		{Forward: netip.MustParseAddr("10.0.0.1"), Return: netip.MustParseAddr("10.0.0.2")},
+		{Return: netip.MustParseAddr("10.0.2.2")},
+	}
+	if got, ok := ipv4opt.CorrelateRR(ops[0].(ipv4opt.RR), dst); !ok || !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Wrong hops, Expected(%v), Got(%v, %v)", expected, got, ok)
+	}
+}
+
+func TestAddressListOptionsAreNotRoutes(t *testing.T) {
+	data := append(append([]byte{}, rrEmptyTest[:11]...), 149, 7, 4, 192, 0, 2, 255, 152, 7, 8, 192, 0, 2, 1)
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	if rrs := ops.RecordRoutes(); len(rrs) != 1 {
+		t.Fatalf("Wrong record routes, Expected(%v), Got(%v)", 1, len(rrs))
+	}
+	if ops.PathTruncated() {
+		t.Fatalf("Exhausted UMP reported as a truncated path")
+	}
+	var n int
+	ops.WalkAddresses(func(ipv4opt.OptionType, ipv4opt.Address) bool {
+		n++
+		return true
+	})
+	if n != 2 {
+		t.Fatalf("Wrong number of addresses, Expected(%v), Got(%v)", 2, n)
+	}
+}
@@ -0,0 +1,298 @@
+package ipv4opt
+
+import "fmt"
+
+// String returns the two character handling restriction code carried in
+// the field. Codes that are not printable ASCII are formatted as hex.
+func (r SecurityHandlingRestriction) String() string {
+	b := []byte{byte(r >> 8), byte(r)}
+	for _, c := range b {
+		if c < 0x20 || c > 0x7e {
+			return fmt.Sprintf("0x%04x", uint16(r))
+		}
+	}
+	return string(b)
+}
+
+// TCCString returns the three character transmission control code carried
+// in the option. Codes that are not printable ASCII are formatted as hex.
+func (s Sec) TCCString() string {
+	b := []byte{byte(s.TCC >> 16), byte(s.TCC >> 8), byte(s.TCC)}
+	for _, c := range b {
+		if c < 0x20 || c > 0x7e {
+			return fmt.Sprintf("0x%06x", uint32(s.TCC))
+		}
+	}
+	return string(b)
+}
+
+// Valid reports whether l is one of the sixteen levels defined by RFC 791.
+func (l SecurityLevel) Valid() bool {
+	_, ok := securityLevelNames[l]
+	return ok
+}
+
+// IsAtLeast reports whether l is as sensitive as other or more. Both levels
+// must be part of the classification hierarchy from Unclassified to
+// TopSecret; for any other level the result is false.
+func (l SecurityLevel) IsAtLeast(other SecurityLevel) bool {
+	r, ok := l.rank()
+	if !ok {
+		return false
+	}
+	o, ok := other.rank()
+	return ok && r >= o
+}
+
+// securityRanks orders the hierarchical security levels from least to most
+// sensitive. EFTO, MMMM, PROG and the reserved levels are not part of the
+// hierarchy and have no rank.
+var securityRanks = map[SecurityLevel]int{
+	Unclassified: 0,
+	Restricted:   1,
+	Confidential: 2,
+	Secret:       3,
+	TopSecret:    4,
+}
+
+// rank returns the position of l in the classification hierarchy.
+func (l SecurityLevel) rank() (int, bool) {
+	r, ok := securityRanks[l]
+	return r, ok
+}
+
+// ErrReservedClassification is returned when the level of an RFC 1108 basic
+// security option is requested and its classification is a reserved one.
+var ErrReservedClassification = fmt.Errorf("Reserved security classification")
+
+// SecurityOption is implemented by the decoded forms of the security option:
+// Sec for the RFC 791 format and BasicSec for the RFC 1108 format.
+type SecurityOption interface {
+	IPOption
+	// SecurityLevel returns the level of the option. ok is false when the
+	// option has no level in the classification hierarchy.
+	SecurityLevel() (level SecurityLevel, ok bool)
+	// AllowedBy reports whether the level of the option does not exceed
+	// maxLevel.
+	AllowedBy(maxLevel SecurityLevel) bool
+}
+
+var (
+	_ SecurityOption = Sec{}
+	_ SecurityOption = BasicSec{}
+)
+
+// allowedBy reports whether level does not exceed maxLevel. Levels outside
+// the classification hierarchy are never allowed.
+func allowedBy(level, maxLevel SecurityLevel) bool {
+	r, ok := level.rank()
+	if !ok {
+		return false
+	}
+	max, ok := maxLevel.rank()
+	if !ok {
+		return false
+	}
+	return r <= max
+}
+
+// SecurityLevel returns the level of the option. ok is always true.
+func (s Sec) SecurityLevel() (SecurityLevel, bool) {
+	return s.Level, true
+}
+
+// AllowedBy reports whether the security level of the option does not exceed
+// maxLevel. Levels outside the classification hierarchy are never allowed.
+func (s Sec) AllowedBy(maxLevel SecurityLevel) bool {
+	return allowedBy(s.Level, maxLevel)
+}
+
+// SecurityLevelOf parses opts and returns the level of its security option,
+// in either format. ErrOptionNotFound is returned if there is no security
+// option, and ErrReservedClassification if it is an RFC 1108 option with a
+// reserved classification.
+func SecurityLevelOf(opts []byte) (SecurityLevel, error) {
+	options, err := Parse(opts)
+	if err != nil {
+		return 0, err
+	}
+	sec, ok := options.Security()
+	if !ok {
+		return 0, ErrOptionNotFound
+	}
+	level, ok := sec.SecurityLevel()
+	if !ok {
+		return 0, ErrReservedClassification
+	}
+	return level, nil
+}
+
+// ESec is the ipv4 extended security option
+type ESec struct {
+	option
+	// Format is the additional security info format code, which identifies
+	// the authority and syntax of Info.
+	Format uint8
+	// Info is the additional security info.
+	Info []byte
+}
+
+const extendedSecurityMinLen = 3
+
+func parseExtendedSecurity(data []byte) (IPOption, error) {
+	var es ESec
+	length, err := optionLength(data, extendedSecurityMinLen)
+	if err != nil {
+		return nil, err
+	}
+	es.option.otype = ExtendedSecurity
+	es.option.length = length
+	es.option.data = make([]byte, es.option.length, es.option.length)
+	copy(es.option.data, data)
+	es.Format = data[2]
+	es.Info = es.option.data[3:]
+	return es, nil
+}
+
+// SecurityFormat selects how security options are decoded.
+type SecurityFormat int
+
+const (
+	// SecurityAuto decodes security options with the 11 byte length of the
+	// RFC 791 format as Sec and all others as RFC 1108 BasicSec, falling
+	// back to Sec if the classification level is not an RFC 1108 level.
+	SecurityAuto SecurityFormat = iota
+	// SecurityRFC791 decodes all security options as the obsolete RFC 791
+	// format into Sec.
+	SecurityRFC791
+	// SecurityRFC1108 decodes all security options as the RFC 1108 basic
+	// security option into BasicSec.
+	SecurityRFC1108
+)
+
+// parser returns the parser for the security option at the start of data.
+func (f SecurityFormat) parser(data []byte) parseFunc {
+	switch f {
+	case SecurityRFC791:
+		return parseSecurity
+	case SecurityRFC1108:
+		return parseBasicSecurity
+	}
+	if len(data) > 2 && data[1] != securityOpLen && Classification(data[2]).valid() {
+		return parseBasicSecurity
+	}
+	return parseSecurity
+}
+
+func parseSecurityAuto(data []byte) (IPOption, error) {
+	return SecurityAuto.parser(data)(data)
+}
+
+// Classification is the classification level of an RFC 1108 basic security
+// option.
+type Classification uint8
+
+const (
+	// BSOReserved4 classification level (reserved for future use).
+	BSOReserved4 Classification = 0x01
+	// BSOTopSecret classification level.
+	BSOTopSecret Classification = 0x3D
+	// BSOSecret classification level.
+	BSOSecret Classification = 0x5A
+	// BSOConfidential classification level.
+	BSOConfidential Classification = 0x96
+	// BSOReserved3 classification level (reserved for future use).
+	BSOReserved3 Classification = 0x66
+	// BSOReserved2 classification level (reserved for future use).
+	BSOReserved2 Classification = 0xCC
+	// BSOUnclassified classification level.
+	BSOUnclassified Classification = 0xAB
+	// BSOReserved1 classification level (reserved for future use).
+	BSOReserved1 Classification = 0xF1
+)
+
+// classificationLevels maps the RFC 1108 classifications to the RFC 791
+// levels of the same name.
+var classificationLevels = map[Classification]SecurityLevel{
+	BSOUnclassified: Unclassified,
+	BSOConfidential: Confidential,
+	BSOSecret:       Secret,
+	BSOTopSecret:    TopSecret,
+}
+
+// Level returns the RFC 791 security level of the same name as c. ok is false
+// for the reserved classifications, which have no such level.
+func (c Classification) Level() (level SecurityLevel, ok bool) {
+	level, ok = classificationLevels[c]
+	return level, ok
+}
+
+func (c Classification) valid() bool {
+	switch c {
+	case BSOReserved4, BSOTopSecret, BSOSecret, BSOConfidential,
+		BSOReserved3, BSOReserved2, BSOUnclassified, BSOReserved1:
+		return true
+	}
+	return false
+}
+
+// ProtectionAuthority is a flag in the first protection authority byte of an
+// RFC 1108 basic security option.
+type ProtectionAuthority uint8
+
+const (
+	// AuthorityGENSER is the Designated Approving Authority per DOD 5200.28.
+	AuthorityGENSER ProtectionAuthority = 0x80
+	// AuthoritySIOPESI is the Joint Chiefs of Staff.
+	AuthoritySIOPESI ProtectionAuthority = 0x40
+	// AuthoritySCI is the Director of Central Intelligence.
+	AuthoritySCI ProtectionAuthority = 0x20
+	// AuthorityNSA is the National Security Agency.
+	AuthorityNSA ProtectionAuthority = 0x10
+	// AuthorityDOE is the Department of Energy.
+	AuthorityDOE ProtectionAuthority = 0x08
+)
+
+// BasicSec is the ipv4 basic security option as defined by RFC 1108
+type BasicSec struct {
+	option
+	Classification Classification
+	// Authorities holds the protection authority flag bytes. The low bit of
+	// each byte is set when another byte follows.
+	Authorities []byte
+}
+
+const basicSecurityMinLen = 3
+
+func parseBasicSecurity(data []byte) (IPOption, error) {
+	var bs BasicSec
+	length, err := optionLength(data, basicSecurityMinLen)
+	if err != nil {
+		return nil, err
+	}
+	bs.option.otype = Security
+	bs.option.length = length
+	bs.option.data = make([]byte, bs.option.length, bs.option.length)
+	copy(bs.option.data, data)
+	bs.Classification = Classification(data[2])
+	bs.Authorities = bs.option.data[3:]
+	return bs, nil
+}
+
+// SecurityLevel returns the RFC 791 level of the same name as the
+// classification of the option. ok is false for reserved classifications.
+func (bs BasicSec) SecurityLevel() (SecurityLevel, bool) {
+	return bs.Classification.Level()
+}
+
+// AllowedBy reports whether the classification of the option does not exceed
+// maxLevel. Reserved classifications are never allowed.
+func (bs BasicSec) AllowedBy(maxLevel SecurityLevel) bool {
+	level, ok := bs.SecurityLevel()
+	return ok && allowedBy(level, maxLevel)
+}
+
+// HasAuthority reports whether the protection authority flag a is set.
+func (bs BasicSec) HasAuthority(a ProtectionAuthority) bool {
+	return len(bs.Authorities) > 0 && bs.Authorities[0]&byte(a) != 0
+}
@@ -0,0 +1,202 @@
+package ipv4opt_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func TestSecurityHandlingRestrictionString(t *testing.T) {
+	ops, err := ipv4opt.Parse(secTest)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	sec := ops[0].(ipv4opt.Sec)
+	if sec.Restriction.String() != "AB" {
+		t.Fatalf("Wrong restriction, Expected(%v), Got(%v)", "AB", sec.Restriction.String())
+	}
+	r := ipv4opt.SecurityHandlingRestriction(0x0102)
+	if r.String() != "0x0102" {
+		t.Fatalf("Wrong restriction, Expected(%v), Got(%v)", "0x0102", r.String())
+	}
+}
+
+func TestSecAllowedBy(t *testing.T) {
+	for _, test := range []struct {
+		level    ipv4opt.SecurityLevel
+		expected bool
+	}{
+		{level: ipv4opt.Unclassified, expected: true},
+		{level: ipv4opt.Confidential, expected: true},
+		{level: ipv4opt.Secret, expected: true},
+		{level: ipv4opt.TopSecret, expected: false},
+		{level: ipv4opt.EFTO, expected: false},
+	} {
+		sec := ipv4opt.Sec{Level: test.level}
+		if sec.AllowedBy(ipv4opt.Secret) != test.expected {
+			t.Fatalf("Wrong result for level %v, Expected(%v), Got(%v)", test.level, test.expected, !test.expected)
+		}
+	}
+}
+
+func TestSecurityLevelOf(t *testing.T) {
+	level, err := ipv4opt.SecurityLevelOf(secTest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if level != ipv4opt.Secret {
+		t.Fatalf("Wrong level, Expected(%v), Got(%v)", ipv4opt.Secret, level)
+	}
+	_, err = ipv4opt.SecurityLevelOf(rrTest)
+	if err != ipv4opt.ErrOptionNotFound {
+		t.Fatalf("Wrong error, Expected(%v), Got(%v)", ipv4opt.ErrOptionNotFound, err)
+	}
+}
+
+func TestBasicSecurityLevel(t *testing.T) {
+	tests := []struct {
+		data    []byte
+		level   ipv4opt.SecurityLevel
+		err     error
+		allowed bool
+	}{
+		{[]byte{130, 3, 0x3d, 0}, ipv4opt.TopSecret, nil, false},
+		{[]byte{130, 4, 0x5a, 0x80, 0, 0, 0, 0}, ipv4opt.Secret, nil, true},
+		{[]byte{130, 3, 0xab, 0}, ipv4opt.Unclassified, nil, true},
+		{[]byte{130, 3, 0xf1, 0}, 0, ipv4opt.ErrReservedClassification, false},
+	}
+	for i, test := range tests {
+		level, err := ipv4opt.SecurityLevelOf(test.data)
+		if err != test.err || level != test.level {
+			t.Fatalf("Test %d, Expected(%v, %v), Got(%v, %v)", i, test.level, test.err, level, err)
+		}
+		ops, err := ipv4opt.Parse(test.data)
+		if err != nil {
+			t.Fatalf("Failed to parse test data: %v", err)
+		}
+		sec, ok := ops.Security()
+		if !ok {
+			t.Fatalf("Test %d, Missing security option", i)
+		}
+		if _, ok := sec.(ipv4opt.BasicSec); !ok {
+			t.Fatalf("Test %d, Wrong option, Expected(BasicSec), Got(%T)", i, sec)
+		}
+		if sec.AllowedBy(ipv4opt.Secret) != test.allowed {
+			t.Fatalf("Test %d, Wrong allowed, Expected(%v), Got(%v)", i, test.allowed, !test.allowed)
+		}
+	}
+}
+
+func TestExtendedSecurity(t *testing.T) {
+	data := []byte{133, 6, 1, 0xAA, 0xBB, 0xCC}
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	es, ok := ops[0].(ipv4opt.ESec)
+	if !ok {
+		t.Fatalf("Wrong option, Expected(ESec), Got(%T)", ops[0])
+	}
+	if es.Type() != ipv4opt.ExtendedSecurity || es.Length() != 6 || es.Format != 1 {
+		t.Fatalf("Wrong extended security option, Got(%v)", es)
+	}
+	if !reflect.DeepEqual(es.Info, []byte{0xAA, 0xBB, 0xCC}) {
+		t.Fatalf("Wrong info, Expected(%v), Got(%v)", []byte{0xAA, 0xBB, 0xCC}, es.Info)
+	}
+	b, err := es.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(b, data) {
+		t.Fatalf("Wrong marshaled data, Expected(%v), Got(%v)", data, b)
+	}
+}
+
+func TestBasicSecurity(t *testing.T) {
+	data := []byte{130, 4, 0x5A, 0x90}
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	bs, ok := ops[0].(ipv4opt.BasicSec)
+	if !ok {
+		t.Fatalf("Wrong option, Expected(BasicSec), Got(%T)", ops[0])
+	}
+	if bs.Type() != ipv4opt.Security || bs.Length() != 4 || bs.Classification != ipv4opt.BSOSecret {
+		t.Fatalf("Wrong basic security option, Got(%v)", bs)
+	}
+	if !bs.HasAuthority(ipv4opt.AuthorityGENSER) || !bs.HasAuthority(ipv4opt.AuthorityNSA) || bs.HasAuthority(ipv4opt.AuthorityDOE) {
+		t.Fatalf("Wrong authorities, Got(%v)", bs.Authorities)
+	}
+	b, err := bs.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(b, data) {
+		t.Fatalf("Wrong marshaled data, Expected(%v), Got(%v)", data, b)
+	}
+
+	ops, err = ipv4opt.Parse(secTest)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	if _, ok := ops[0].(ipv4opt.Sec); !ok {
+		t.Fatalf("Wrong option, Expected(Sec), Got(%T)", ops[0])
+	}
+	ops, err = ipv4opt.ParseWithSecurityFormat(secTest, ipv4opt.SecurityRFC1108)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	if _, ok := ops[0].(ipv4opt.BasicSec); !ok {
+		t.Fatalf("Wrong option, Expected(BasicSec), Got(%T)", ops[0])
+	}
+}
+
+func TestSecTCC(t *testing.T) {
+	data := []byte{130, 11, 0xD7, 0x88, 0, 0, 'A', 'B', 'X', 'Y', 'Z'}
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	sec := ops[0].(ipv4opt.Sec)
+	if sec.TCC != 0x58595a {
+		t.Fatalf("Wrong TCC, Expected(%#x), Got(%#x)", 0x58595a, sec.TCC)
+	}
+	if sec.TCCString() != "XYZ" {
+		t.Fatalf("Wrong TCC, Expected(%v), Got(%v)", "XYZ", sec.TCCString())
+	}
+	b, err := sec.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(b, data) {
+		t.Fatalf("Wrong marshaled data, Expected(%v), Got(%v)", data, b)
+	}
+	if s := (ipv4opt.Sec{TCC: 0x000102}).TCCString(); s != "0x000102" {
+		t.Fatalf("Wrong TCC, Expected(%v), Got(%v)", "0x000102", s)
+	}
+}
+
+func TestSecurityLevelCompare(t *testing.T) {
+	for _, test := range []struct {
+		level, other ipv4opt.SecurityLevel
+		valid        bool
+		atLeast      bool
+	}{
+		{ipv4opt.Secret, ipv4opt.Confidential, true, true},
+		{ipv4opt.Confidential, ipv4opt.Confidential, true, true},
+		{ipv4opt.Restricted, ipv4opt.Confidential, true, false},
+		{ipv4opt.TopSecret, ipv4opt.Unclassified, true, true},
+		{ipv4opt.EFTO, ipv4opt.Unclassified, true, false},
+		{ipv4opt.Secret, ipv4opt.PROG, true, false},
+		{0x1234, ipv4opt.Unclassified, false, false},
+	} {
+		if test.level.Valid() != test.valid {
+			t.Fatalf("Wrong validity for %v, Expected(%v), Got(%v)", test.level, test.valid, !test.valid)
+		}
+		if test.level.IsAtLeast(test.other) != test.atLeast {
+			t.Fatalf("Wrong comparison of %v with %v, Expected(%v), Got(%v)", test.level, test.other, test.atLeast, !test.atLeast)
+		}
+	}
+}
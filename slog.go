@@ -0,0 +1,168 @@
+package ipv4opt
+
+import (
+	"log/slog"
+	"strconv"
+)
+
+var _ slog.LogValuer = Options(nil)
+
+// logGroup returns a group holding the type and length of opt followed by
+// attrs.
+func logGroup(opt IPOption, attrs ...slog.Attr) slog.Value {
+	return slog.GroupValue(append([]slog.Attr{
+		slog.String("type", opt.Type().String()),
+		slog.Int("length", opt.Length()),
+	}, attrs...)...)
+}
+
+// logValue returns the log value of opt, using its LogValue method if it has
+// one and its payload otherwise.
+func logValue(opt IPOption) slog.Value {
+	if v, ok := opt.(slog.LogValuer); ok {
+		return v.LogValue()
+	}
+	return logGroup(opt, slog.Any("payload", opt.Payload()))
+}
+
+// LogValue logs the options as a group with one group per option, keyed by
+// its index.
+func (o Options) LogValue() slog.Value {
+	attrs := make([]slog.Attr, len(o))
+	for i, opt := range o {
+		attrs[i] = slog.Attr{Key: strconv.Itoa(i), Value: logValue(opt)}
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue logs the option as a group of its type and length.
+func (opt EOOList) LogValue() slog.Value {
+	return logGroup(opt)
+}
+
+// LogValue logs the option as a group of its type and length.
+func (opt NoOp) LogValue() slog.Value {
+	return logGroup(opt)
+}
+
+// LogValue logs the option as a group of its type, length and fields.
+func (s Sec) LogValue() slog.Value {
+	return logGroup(s,
+		slog.String("level", s.Level.String()),
+		slog.Any("compartment", s.Compartment),
+		slog.String("restriction", s.Restriction.String()),
+		slog.String("tcc", s.TCCString()),
+	)
+}
+
+// LogValue logs the option as a group of its type, length and fields.
+func (bs BasicSec) LogValue() slog.Value {
+	return logGroup(bs,
+		slog.Any("classification", bs.Classification),
+		slog.Any("authorities", bs.Authorities),
+	)
+}
+
+// LogValue logs the option as a group of its type, length and fields.
+func (es ESec) LogValue() slog.Value {
+	return logGroup(es,
+		slog.Any("format", es.Format),
+		slog.Any("info", es.Info),
+	)
+}
+
+// LogValue logs the option as a group of its type, length, DOI and tags.
+func (c CIPSO) LogValue() slog.Value {
+	return logGroup(c,
+		slog.Any("doi", c.DOI),
+		slog.Any("tags", c.Tags),
+	)
+}
+
+// LogValue logs the option as a group of its type, length, pointer and
+// routes, with one group per route keyed by its index.
+func (l AddressList) LogValue() slog.Value {
+	routes := make([]slog.Attr, len(l.Routes))
+	for i, r := range l.Routes {
+		routes[i] = slog.Group(strconv.Itoa(i), slog.String("addr", r.Addr().String()))
+	}
+	return logGroup(l,
+		slog.Int("pointer", int(l.Pointer)),
+		slog.Attr{Key: "routes", Value: slog.GroupValue(routes...)},
+	)
+}
+
+// LogValue logs the option as a group of its type, length and value.
+func (u UInt16Option) LogValue() slog.Value {
+	return logGroup(u, slog.Any("value", u.Value))
+}
+
+// LogValue logs the option as a group of its type, length and ID.
+func (s StreamID) LogValue() slog.Value {
+	return logGroup(s, slog.Any("id", s.ID))
+}
+
+// LogValue logs the option as a group of its type, length and value.
+func (ra RouterAlert) LogValue() slog.Value {
+	return logGroup(ra, slog.Any("value", ra.Value))
+}
+
+// LogValue logs the option as a group of its type, length and MTU.
+func (m MTUProbe) LogValue() slog.Value {
+	return logGroup(m, slog.Any("mtu", m.MTU))
+}
+
+// LogValue logs the option as a group of its type, length and MTU.
+func (m MTUReply) LogValue() slog.Value {
+	return logGroup(m, slog.Any("mtu", m.MTU))
+}
+
+// LogValue logs the option as a group of its type, length and fields.
+func (tr Traceroute) LogValue() slog.Value {
+	return logGroup(tr,
+		slog.Any("id", tr.ID),
+		slog.Any("outbound_hops", tr.OutboundHops),
+		slog.Any("return_hops", tr.ReturnHops),
+		slog.String("originator", tr.Originator.String()),
+	)
+}
+
+// LogValue logs the option as a group of its type, length and fields.
+func (qs QuickStart) LogValue() slog.Value {
+	return logGroup(qs,
+		slog.Any("function", qs.Function),
+		slog.Any("rate", qs.Rate),
+		slog.Any("ttl", qs.TTL),
+		slog.Any("nonce", qs.Nonce),
+	)
+}
+
+// LogValue logs the option as a group of its type, length and fields, with
+// one group per stamp keyed by its index. The address of a stamp is left out
+// for TSOnly options.
+func (ts TS) LogValue() slog.Value {
+	stamps := make([]slog.Attr, len(ts.Stamps))
+	for i, st := range ts.Stamps {
+		attrs := []slog.Attr{slog.Uint64("time", uint64(st.Time))}
+		if ts.Flags != TSOnly {
+			attrs = append(attrs, slog.String("addr", st.Addr.Addr().String()))
+		}
+		stamps[i] = slog.Attr{Key: strconv.Itoa(i), Value: slog.GroupValue(attrs...)}
+	}
+	return logGroup(ts,
+		slog.Int("pointer", int(ts.Pointer)),
+		slog.String("flags", ts.Flags.String()),
+		slog.Int("overflow", int(ts.Over)),
+		slog.Attr{Key: "stamps", Value: slog.GroupValue(stamps...)},
+	)
+}
+
+// LogValue logs the option as a group of its type, length and payload.
+func (exp Experimental) LogValue() slog.Value {
+	return logGroup(exp, slog.Any("payload", exp.Value))
+}
+
+// LogValue logs the option as a group of its type, length and value.
+func (raw RawOption) LogValue() slog.Value {
+	return logGroup(raw, slog.Any("value", raw.Value))
+}
@@ -0,0 +1,42 @@
+package ipv4opt_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func TestLogValue(t *testing.T) {
+	data := append(append([]byte{148, 4, 0, 0, 1}, rrEmptyTest[:11]...), 31, 3, 0xff, 68, 12, 13, 1, 192, 0, 2, 1, 0, 0, 0, 5)
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("packet", "options", ops)
+	var entry struct {
+		Options map[string]map[string]interface{}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Failed to decode log entry %s: %v", buf.Bytes(), err)
+	}
+	expected := map[string]map[string]interface{}{
+		"0": {"type": "RouterAlertOption(148)", "length": 4.0, "value": 0.0},
+		"1": {"type": "NoOperation(1)", "length": 1.0},
+		"2": {"type": "RecordRoute(7)", "length": 11.0, "pointer": 4.0, "routes": map[string]interface{}{
+			"0": map[string]interface{}{"addr": "0.0.0.0"},
+			"1": map[string]interface{}{"addr": "0.0.0.0"},
+		}},
+		"3": {"type": "OptionType(31)", "length": 3.0, "value": "/w=="},
+		"4": {"type": "InternetTimestamp(68)", "length": 12.0, "pointer": 13.0, "flags": "TSAndAddr", "overflow": 0.0, "stamps": map[string]interface{}{
+			"0": map[string]interface{}{"time": 5.0, "addr": "192.0.2.1"},
+		}},
+	}
+	if !reflect.DeepEqual(entry.Options, expected) {
+		t.Fatalf("Wrong log entry, Expected(%v), Got(%v)", expected, entry.Options)
+	}
+}
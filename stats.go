@@ -0,0 +1,147 @@
+package ipv4opt
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// Stats counts what the parsers it is attached to have processed. The zero
+// value is ready to use and a Stats may be shared by parsers running
+// concurrently.
+type Stats struct {
+	buffers atomic.Uint64
+	bytes   atomic.Uint64
+	options [256]atomic.Uint64
+
+	mu     sync.Mutex
+	errors map[string]uint64
+}
+
+// StatsSnapshot is a copy of the counters of a Stats at one point in time.
+type StatsSnapshot struct {
+	// Buffers is the number of option buffers parsed.
+	Buffers uint64
+	// Bytes is the total length of those buffers.
+	Bytes uint64
+	// Options is the number of options parsed of each type.
+	Options map[OptionType]uint64
+	// Errors is the number of parse errors of each kind, keyed by the
+	// message of the package's sentinel error the error is, such as
+	// ErrTruncatedOption, or "other" for any other error.
+	Errors map[string]uint64
+}
+
+// WithStats records the work of the parser's Parse, ParseInto and
+// ParseLenient methods in s.
+func WithStats(s *Stats) ParserOption {
+	return func(p *Parser) {
+		p.stats = s
+	}
+}
+
+// Buffers returns the number of option buffers parsed.
+func (s *Stats) Buffers() uint64 {
+	return s.buffers.Load()
+}
+
+// Bytes returns the total length of the option buffers parsed.
+func (s *Stats) Bytes() uint64 {
+	return s.bytes.Load()
+}
+
+// Options returns the number of options of type t parsed.
+func (s *Stats) Options(t OptionType) uint64 {
+	return s.options[t].Load()
+}
+
+// Errors returns the number of parse errors of each kind.
+func (s *Stats) Errors() map[string]uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	errs := make(map[string]uint64, len(s.errors))
+	for k, n := range s.errors {
+		errs[k] = n
+	}
+	return errs
+}
+
+// Snapshot returns a copy of all counters. Option types that were never
+// parsed are left out.
+func (s *Stats) Snapshot() StatsSnapshot {
+	snap := StatsSnapshot{
+		Buffers: s.Buffers(),
+		Bytes:   s.Bytes(),
+		Options: make(map[OptionType]uint64),
+		Errors:  s.Errors(),
+	}
+	for t := range s.options {
+		if n := s.options[t].Load(); n > 0 {
+			snap.Options[OptionType(t)] = n
+		}
+	}
+	return snap
+}
+
+// Reset sets all counters to zero.
+func (s *Stats) Reset() {
+	s.buffers.Store(0)
+	s.bytes.Store(0)
+	for t := range s.options {
+		s.options[t].Store(0)
+	}
+	s.mu.Lock()
+	s.errors = nil
+	s.mu.Unlock()
+}
+
+// record counts one call to parse on opts that produced options and errs.
+func (s *Stats) record(opts []byte, options Options, errs []error) {
+	s.buffers.Add(1)
+	s.bytes.Add(uint64(len(opts)))
+	for _, opt := range options {
+		s.options[opt.Type()].Add(1)
+	}
+	if len(errs) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.errors == nil {
+		s.errors = make(map[string]uint64)
+	}
+	for _, err := range errs {
+		s.errors[errorKind(err)]++
+	}
+}
+
+// statsErrors are the sentinel errors parse errors are counted under, most
+// specific first as some of them wrap others.
+var statsErrors = []error{
+	ErrTruncatedOption,
+	ErrBadTimestampLength,
+	ErrInvalidOptionLength,
+	ErrIncorrectRRLength,
+	ErrOptionType,
+	ErrOptionDataTooLarge,
+	ErrTooManyOptions,
+	ErrNonZeroPadding,
+	ErrBadTimestampFlag,
+	ErrBadPointer,
+}
+
+// otherErrorKind is the kind of errors that are none of statsErrors, such as
+// those returned by parsers registered outside the package.
+const otherErrorKind = "other"
+
+// errorKind returns the message of the sentinel error err is, or
+// otherErrorKind. Keying on the sentinels keeps the number of kinds bounded
+// however many distinct errors are seen.
+func errorKind(err error) string {
+	for _, sentinel := range statsErrors {
+		if errors.Is(err, sentinel) {
+			return sentinel.Error()
+		}
+	}
+	return otherErrorKind
+}
@@ -0,0 +1,57 @@
+package ipv4opt_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func TestStats(t *testing.T) {
+	var stats ipv4opt.Stats
+	p := ipv4opt.NewParser(ipv4opt.WithStats(&stats))
+	inputs := [][]byte{
+		{148, 4, 0, 0},
+		{1, 1, 148, 4, 0, 0},
+		{7, 7, 4},
+		{7, 10, 4, 0, 0, 0, 0, 0, 0, 0, 1, 1},
+		make([]byte, 41),
+	}
+	for _, data := range inputs {
+		p.ParseLenient(data)
+	}
+	if stats.Buffers() != 5 {
+		t.Fatalf("Wrong buffer count, Expected(%v), Got(%v)", 5, stats.Buffers())
+	}
+	if stats.Bytes() != 4+6+3+12+41 {
+		t.Fatalf("Wrong byte count, Expected(%v), Got(%v)", 4+6+3+12+41, stats.Bytes())
+	}
+	for otype, n := range map[ipv4opt.OptionType]uint64{ipv4opt.RouterAlertOption: 2, ipv4opt.NoOperation: 4, ipv4opt.RecordRoute: 0} {
+		if stats.Options(otype) != n {
+			t.Fatalf("Wrong count for %v, Expected(%v), Got(%v)", otype, n, stats.Options(otype))
+		}
+	}
+	errs := stats.Errors()
+	for _, err := range []error{ipv4opt.ErrTruncatedOption, ipv4opt.ErrIncorrectRRLength, ipv4opt.ErrOptionDataTooLarge} {
+		if errs[err.Error()] != 1 {
+			t.Fatalf("Wrong count for %v, Expected(%v), Got(%v)", err, 1, errs)
+		}
+	}
+	p = ipv4opt.NewParser(ipv4opt.WithStats(&stats), ipv4opt.WithParser(200, func(data []byte) (ipv4opt.IPOption, error) {
+		return nil, fmt.Errorf("bad option %v", data)
+	}))
+	for _, data := range [][]byte{{200, 2}, {200, 3, 1}} {
+		p.ParseLenient(data)
+	}
+	if errs := stats.Errors(); errs["other"] != 2 || len(errs) != 4 {
+		t.Fatalf("Wrong count for other errors, Expected(%v), Got(%v)", 2, errs)
+	}
+	snap := stats.Snapshot()
+	if len(snap.Options) != 2 || snap.Buffers != 7 {
+		t.Fatalf("Wrong snapshot, Got(%+v)", snap)
+	}
+	stats.Reset()
+	if stats.Buffers() != 0 || stats.Options(ipv4opt.NoOperation) != 0 || len(stats.Errors()) != 0 {
+		t.Fatalf("Stats were not reset, Got(%+v)", stats.Snapshot())
+	}
+}
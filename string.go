@@ -0,0 +1,223 @@
+package ipv4opt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// optionTypeNames are the names of the option type constants.
+var optionTypeNames = map[OptionType]string{
+	EndOfOptionList:         "EndOfOptionList",
+	NoOperation:             "NoOperation",
+	Security:                "Security",
+	LooseSourceRecordRoute:  "LooseSourceRecordRoute",
+	StrictSourceRecordRoute: "StrictSourceRecordRoute",
+	RecordRoute:             "RecordRoute",
+	StreamIdentifier:        "StreamIdentifier",
+	InternetTimestamp:       "InternetTimestamp",
+	RouterAlertOption:       "RouterAlertOption",
+	CommercialSecurity:      "CommercialSecurity",
+	ExtendedSecurity:        "ExtendedSecurity",
+	MTUProbeOption:          "MTUProbeOption",
+	MTUReplyOption:          "MTUReplyOption",
+	TracerouteOption:        "TracerouteOption",
+	QuickStartOption:        "QuickStartOption",
+	ExperimentalOption30:    "ExperimentalOption30",
+	ExperimentalOption94:    "ExperimentalOption94",
+	ExperimentalOption158:   "ExperimentalOption158",
+	ExperimentalOption222:   "ExperimentalOption222",
+	// Historical options.
+	ZSUOption:              "ZSUOption",
+	EncodeOption:           "EncodeOption",
+	VISAOption:             "VISAOption",
+	IMITDOption:            "IMITDOption",
+	EIPOption:              "EIPOption",
+	AddressExtensionOption: "AddressExtensionOption",
+	SDBOption:              "SDBOption",
+	DPSOption:              "DPSOption",
+	UMPOption:              "UMPOption",
+	FINNOption:             "FINNOption",
+}
+
+// String returns the name of the option type constant followed by its
+// number, such as "RecordRoute(7)".
+func (t OptionType) String() string {
+	if name, ok := optionTypeNames[t]; ok {
+		return fmt.Sprintf("%s(%d)", name, uint8(t))
+	}
+	return fmt.Sprintf("OptionType(%d)", uint8(t))
+}
+
+// String returns the name of the flag constant, such as "TSAndAddr".
+func (f Flag) String() string {
+	switch f {
+	case TSOnly:
+		return "TSOnly"
+	case TSAndAddr:
+		return "TSAndAddr"
+	case TSPrespec:
+		return "TSPrespec"
+	}
+	return fmt.Sprintf("Flag(%d)", uint8(f))
+}
+
+// String returns the overflow count, such as "Overflow(3)".
+func (o Overflow) String() string {
+	return fmt.Sprintf("Overflow(%d)", uint8(o))
+}
+
+var securityLevelNames = map[SecurityLevel]string{
+	Unclassified: "Unclassified",
+	Confidential: "Confidential",
+	EFTO:         "EFTO",
+	MMMM:         "MMMM",
+	PROG:         "PROG",
+	Restricted:   "Restricted",
+	Secret:       "Secret",
+	TopSecret:    "TopSecret",
+	Reserved0:    "Reserved0",
+	Reserved1:    "Reserved1",
+	Reserved2:    "Reserved2",
+	Reserved3:    "Reserved3",
+	Reserved4:    "Reserved4",
+	Reserved5:    "Reserved5",
+	Reserved6:    "Reserved6",
+	Reserved7:    "Reserved7",
+}
+
+// String returns the name of the security level constant, such as
+// "TopSecret".
+func (l SecurityLevel) String() string {
+	if name, ok := securityLevelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("SecurityLevel(%#04x)", uint16(l))
+}
+
+// String returns the options in tcpdump style, separated by commas.
+func (o Options) String() string {
+	parts := make([]string, len(o))
+	for i, opt := range o {
+		if s, ok := opt.(fmt.Stringer); ok {
+			parts[i] = s.String()
+			continue
+		}
+		parts[i] = fmt.Sprintf("%s{%x}", optionName(opt.Type()), dataOf(opt))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (opt EOOList) String() string {
+	return "EOL"
+}
+
+func (opt NoOp) String() string {
+	return "NOP"
+}
+
+func (s Sec) String() string {
+	return fmt.Sprintf("SEC{level=%v comp=%#04x restr=%v tcc=%#06x}", s.Level, s.Compartment, s.Restriction, s.TCC)
+}
+
+func (bs BasicSec) String() string {
+	return fmt.Sprintf("SEC{class=%#02x auth=%x}", bs.Classification, bs.Authorities)
+}
+
+func (es ESec) String() string {
+	return fmt.Sprintf("E-SEC{fmt=%d info=%x}", es.Format, es.Info)
+}
+
+func (c CIPSO) String() string {
+	tags := make([]string, len(c.Tags))
+	for i, tag := range c.Tags {
+		tags[i] = fmt.Sprintf("%d/%d", tag.Type, tag.Level)
+	}
+	return fmt.Sprintf("CIPSO{doi=%d tags=%s}", c.DOI, strings.Join(tags, ","))
+}
+
+// String returns the address list in tcpdump style, with the name of the
+// option, its pointer and each route slot.
+func (l AddressList) String() string {
+	name := "RR"
+	switch l.Type() {
+	case LooseSourceRecordRoute:
+		name = "LSRR"
+	case StrictSourceRecordRoute:
+		name = "SSRR"
+	case SDBOption:
+		name = "SDB"
+	case UMPOption:
+		name = "UMP"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s{ptr=%d", name, l.Pointer)
+	for _, r := range l.Routes {
+		b.WriteByte(' ')
+		b.WriteString(r.String())
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func (s StreamID) String() string {
+	return fmt.Sprintf("SID{%d}", s.ID)
+}
+
+// String returns the timestamp option in tcpdump style. Stamps carrying an
+// address are written as address@time.
+func (ts TS) String() string {
+	var b strings.Builder
+	b.WriteString("TS{")
+	switch ts.Flags {
+	case TSOnly:
+		b.WriteString("TSONLY")
+	case TSAndAddr:
+		b.WriteString("TS+ADDR")
+	case TSPrespec:
+		b.WriteString("PRESPEC")
+	default:
+		fmt.Fprintf(&b, "[bad flag %d]", ts.Flags)
+	}
+	fmt.Fprintf(&b, " ptr=%d oflw=%d", ts.Pointer, ts.Over)
+	for _, s := range ts.Stamps {
+		if ts.Flags == TSOnly {
+			fmt.Fprintf(&b, " %d", s.Time)
+			continue
+		}
+		fmt.Fprintf(&b, " %v@%d", s.Addr, s.Time)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func (ra RouterAlert) String() string {
+	return fmt.Sprintf("RTRALT{%d}", ra.Value)
+}
+
+func (m MTUProbe) String() string {
+	return fmt.Sprintf("MTUP{%d}", m.MTU)
+}
+
+func (m MTUReply) String() string {
+	return fmt.Sprintf("MTUR{%d}", m.MTU)
+}
+
+func (tr Traceroute) String() string {
+	return fmt.Sprintf("TR{id=%d out=%d ret=%d orig=%v}", tr.ID, tr.OutboundHops, tr.ReturnHops, tr.Originator)
+}
+
+func (qs QuickStart) String() string {
+	return fmt.Sprintf("QS{func=%d rate=%d ttl=%d nonce=%#x}", qs.Function, qs.Rate, qs.TTL, qs.Nonce)
+}
+
+func (u UInt16Option) String() string {
+	return fmt.Sprintf("%s{%d}", optionName(u.Type()), u.Value)
+}
+
+func (exp Experimental) String() string {
+	return fmt.Sprintf("%s{%x}", optionName(exp.Type()), exp.Value)
+}
+
+func (raw RawOption) String() string {
+	return fmt.Sprintf("%s{%x}", optionName(raw.Type()), raw.Value)
+}
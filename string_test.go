@@ -0,0 +1,53 @@
+package ipv4opt_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func TestOptionsString(t *testing.T) {
+	tests := []struct {
+		data     []byte
+		expected string
+	}{
+		{rrEmptyTest, "RR{ptr=4 0.0.0.0 0.0.0.0}, EOL"},
+		{[]byte{131, 7, 8, 10, 0, 0, 1}, "LSRR{ptr=8 10.0.0.1}"},
+		{tsPreSpec[:12], "TS{PRESPEC ptr=13 oflw=4 66.109.38.50@47215085}"},
+		{[]byte{68, 8, 9, 0, 0, 0, 0, 42}, "TS{TSONLY ptr=9 oflw=0 42}"},
+		{[]byte{1, 136, 4, 0x12, 0x34, 0}, "NOP, SID{4660}, EOL"},
+		{[]byte{148, 4, 0, 0, 222, 4, 1, 2}, "RTRALT{0}, EXP222{0102}"},
+	}
+	for _, test := range tests {
+		ops, err := ipv4opt.Parse(test.data)
+		if err != nil {
+			t.Fatalf("Failed to parse test data: %v", err)
+		}
+		if got := ops.String(); got != test.expected {
+			t.Fatalf("Expected(%v), Got(%v)", test.expected, got)
+		}
+	}
+}
+
+func TestConstantStrings(t *testing.T) {
+	tests := []struct {
+		value    fmt.Stringer
+		expected string
+	}{
+		{ipv4opt.OptionType(ipv4opt.RecordRoute), "RecordRoute(7)"},
+		{ipv4opt.OptionType(ipv4opt.RouterAlertOption), "RouterAlertOption(148)"},
+		{ipv4opt.OptionType(200), "OptionType(200)"},
+		{ipv4opt.Flag(ipv4opt.TSAndAddr), "TSAndAddr"},
+		{ipv4opt.Flag(2), "Flag(2)"},
+		{ipv4opt.SecurityLevel(ipv4opt.TopSecret), "TopSecret"},
+		{ipv4opt.Unclassified, "Unclassified"},
+		{ipv4opt.SecurityLevel(0x1234), "SecurityLevel(0x1234)"},
+		{ipv4opt.Overflow(3), "Overflow(3)"},
+	}
+	for _, test := range tests {
+		if got := test.value.String(); got != test.expected {
+			t.Fatalf("Expected(%v), Got(%v)", test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package ipv4opt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"slices"
+	"time"
+)
+
+// msPerDay is the number of milliseconds in a day. Standard timestamps are
+// milliseconds since midnight UT and wrap at this value.
+const msPerDay = 24 * 60 * 60 * 1000
+
+var (
+	// ErrTimestampOrder is returned when timestamps across a sequence of
+	// packets go backwards.
+	ErrTimestampOrder = fmt.Errorf("Timestamps are not monotonic")
+	// ErrZeroAddress is returned when a stamp that must carry an address
+	// has none.
+	ErrZeroAddress = fmt.Errorf("Timestamp entry has no address")
+	// ErrBadTimestampFlag is returned when a timestamp option has a flag
+	// other than TSOnly, TSAndAddr or TSPrespec.
+	ErrBadTimestampFlag = fmt.Errorf("Invalid timestamp flag")
+	// ErrBadTimestampLength is returned when the data of a timestamp option
+	// is not a whole number of entries of the size its flag selects.
+	ErrBadTimestampLength = fmt.Errorf("%w: timestamp data is not a whole number of entries", ErrInvalidOptionLength)
+	// ErrOverflowFull is returned when a hop can not be counted because the
+	// overflow count of a full timestamp option is already at its maximum.
+	// RFC 791 requires the datagram to be discarded.
+	ErrOverflowFull = fmt.Errorf("Timestamp overflow count is full")
+	// ErrPrespecMismatch is returned when a prespecified timestamp response
+	// does not carry the addresses of the request.
+	ErrPrespecMismatch = fmt.Errorf("Prespecified addresses do not match")
+)
+
+// nonStandardBit marks a timestamp that is not in milliseconds since
+// midnight UT.
+const nonStandardBit = 0x80000000
+
+// NonStandard reports whether the high-order bit of the timestamp is set,
+// which RFC 791 uses to mark a time that is not in milliseconds since
+// midnight UT or is not available in that form.
+func (t Timestamp) NonStandard() bool {
+	return t&nonStandardBit != 0
+}
+
+// Duration returns the time since midnight UT carried in the timestamp.
+// The high-order bit is ignored; check NonStandard before relying on the
+// result.
+func (t Timestamp) Duration() time.Duration {
+	return time.Duration(t&^nonStandardBit) * time.Millisecond
+}
+
+// Time returns the timestamp as a time on the UTC day of date.
+func (t Timestamp) Time(date time.Time) time.Time {
+	y, m, d := date.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Add(t.Duration())
+}
+
+// timestampOf returns t as milliseconds since midnight UT.
+func timestampOf(t time.Time) Timestamp {
+	t = t.UTC()
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return Timestamp(t.Sub(midnight) / time.Millisecond)
+}
+
+// firstTimestamp returns the first timestamp recorded in a timestamp option.
+func firstTimestamp(o Options) (Timestamp, bool) {
+	for _, opt := range o {
+		ts, ok := opt.(TS)
+		if !ok || len(ts.Stamps) == 0 || ts.Pointer <= 5 {
+			continue
+		}
+		return ts.Stamps[0].Time, true
+	}
+	return 0, false
+}
+
+// CheckTimestampMonotonic checks that the first recorded timestamp of each
+// packet in captures is not earlier than that of the packet before it.
+// Timestamps that wrap at midnight are treated as moving forward. Packets
+// without a recorded timestamp are skipped.
+func CheckTimestampMonotonic(captures []Options) error {
+	var prev Timestamp
+	prevIdx := -1
+	for i, c := range captures {
+		t, ok := firstTimestamp(c)
+		if !ok {
+			continue
+		}
+		if prevIdx >= 0 {
+			d := (int64(t) - int64(prev) + msPerDay) % msPerDay
+			if d > msPerDay/2 {
+				return fmt.Errorf("%w: capture %d (%d) is before capture %d (%d)", ErrTimestampOrder, i, t, prevIdx, prev)
+			}
+		}
+		prev, prevIdx = t, i
+	}
+	return nil
+}
+
+// entryLen returns the length of a single entry for the option's flag.
+func (ts TS) entryLen() int {
+	if ts.Flags == TSOnly {
+		return 4
+	}
+	return 8
+}
+
+// recordedEntries returns the number of entries before the pointer.
+func (ts TS) recordedEntries() int {
+	if ts.Pointer < 5 {
+		return 0
+	}
+	n := (int(ts.Pointer) - 5) / ts.entryLen()
+	if n > len(ts.Stamps) {
+		return len(ts.Stamps)
+	}
+	return n
+}
+
+// Recorded returns the entries before the pointer, which have been filled
+// in. For TSPrespec, entries at and after the pointer carry addresses but no
+// timestamps yet.
+func (ts TS) Recorded() []Stamp {
+	return ts.Stamps[:ts.recordedEntries()]
+}
+
+// Remaining returns the number of entries at and after the pointer that have
+// not been filled in yet.
+func (ts TS) Remaining() int {
+	return len(ts.Stamps) - ts.recordedEntries()
+}
+
+// OverflowedHops returns the number of hops that could not record a
+// timestamp because the option was full. Per RFC 791 the count is 4 bits
+// wide; a datagram whose count would pass 15 is discarded rather than
+// forwarded, so the count never wraps.
+func (ts TS) OverflowedHops() int {
+	return int(ts.Over)
+}
+
+// encode appends the wire format of the option built from its fields to dst.
+func (ts TS) encode(dst []byte) []byte {
+	length := 4 + ts.entryLen()*len(ts.Stamps)
+	b := slices.Grow(dst, length)
+	b = append(b, InternetTimestamp, byte(length), ts.Pointer, byte(ts.Over)<<4|byte(ts.Flags)&0x0f)
+	for _, s := range ts.Stamps {
+		if ts.Flags != TSOnly {
+			b = binary.BigEndian.AppendUint32(b, uint32(s.Addr))
+		}
+		b = binary.BigEndian.AppendUint32(b, uint32(s.Time))
+	}
+	return b
+}
+
+// Recompute rebuilds the length and data of the option from its fields. It
+// must be called after the pointer, flags, overflow or stamps are changed.
+func (ts *TS) Recompute() {
+	ts.option.otype = InternetTimestamp
+	ts.option.data = ts.encode(nil)
+	ts.option.length = len(ts.option.data)
+}
+
+// NewTimestampAddr returns a TSAndAddr timestamp option with stamps already
+// recorded, followed by extraSlots empty entries for later hops. The pointer
+// is set to the first empty entry.
+func NewTimestampAddr(stamps []Stamp, extraSlots int) (TS, error) {
+	if extraSlots < 0 {
+		return TS{}, ErrInvalidOptionLength
+	}
+	for _, s := range stamps {
+		if s.Addr == 0 {
+			return TS{}, ErrZeroAddress
+		}
+	}
+	ts := TS{
+		Pointer: byte(5 + 8*len(stamps)),
+		Flags:   TSAndAddr,
+		Stamps:  make([]Stamp, len(stamps)+extraSlots),
+	}
+	if 4+8*len(ts.Stamps) > MaxOptionsLen {
+		return TS{}, ErrOptionDataTooLarge
+	}
+	copy(ts.Stamps, stamps)
+	ts.Recompute()
+	return ts, nil
+}
+
+// NewTimestampOption returns an empty timestamp option with the given flag
+// and number of entries, and the pointer set to the first entry. For
+// TSPrespec, the addresses in prespec fill the first entries and slots must be
+// at least len(prespec); the other flags take no prespecified addresses.
+func NewTimestampOption(flag Flag, slots int, prespec ...net.IP) (TS, error) {
+	switch flag {
+	case TSOnly, TSAndAddr:
+		if len(prespec) > 0 {
+			return TS{}, fmt.Errorf("%w: addresses can only be prespecified with TSPrespec", ErrBadTimestampFlag)
+		}
+	case TSPrespec:
+		if len(prespec) == 0 || slots < len(prespec) {
+			return TS{}, ErrInvalidOptionLength
+		}
+	default:
+		return TS{}, ErrBadTimestampFlag
+	}
+	if slots < 1 {
+		return TS{}, ErrInvalidOptionLength
+	}
+	ts := TS{
+		Pointer: 5,
+		Flags:   flag,
+		Stamps:  make([]Stamp, slots),
+	}
+	if 4+ts.entryLen()*slots > MaxOptionsLen {
+		return TS{}, ErrOptionDataTooLarge
+	}
+	for i, ip := range prespec {
+		addr, err := AddressFromNetIP(ip)
+		if err != nil {
+			return TS{}, err
+		}
+		if addr == 0 {
+			return TS{}, ErrZeroAddress
+		}
+		ts.Stamps[i].Addr = addr
+	}
+	ts.Recompute()
+	return ts, nil
+}
+
+// RoundTrip returns the outbound and return timestamps of a prespecified
+// timestamp option used for a round trip measurement. The option is assumed
+// to hold exactly two entries: the first prespecifies the remote host and is
+// stamped on the way out, the second prespecifies the sender and is stamped
+// when the reply arrives back. ok is false unless both entries were stamped.
+func (ts TS) RoundTrip() (out, back Timestamp, ok bool) {
+	if ts.Flags != TSPrespec || len(ts.Stamps) != 2 || ts.Pointer < 21 {
+		return 0, 0, false
+	}
+	return ts.Stamps[0].Time, ts.Stamps[1].Time, true
+}
+
+// PrespecResult is an entry of a prespecified timestamp request along with
+// the answer to it.
+type PrespecResult struct {
+	Addr Address
+	// Time is the timestamp recorded by Addr, valid only when Answered is
+	// true.
+	Time     Timestamp
+	Answered bool
+}
+
+// MatchPrespec pairs the prespecified addresses of request with the
+// timestamps filled in by the responders in response. Entries before the
+// pointer of response were answered; the others were not, because the hop
+// was not on the path or did not stamp the option. Both options must be
+// TSPrespec and response must carry the addresses of request, in order, or
+// ErrPrespecMismatch is returned.
+func MatchPrespec(request, response TS) ([]PrespecResult, error) {
+	for _, ts := range []TS{request, response} {
+		if ts.Flags != TSPrespec {
+			return nil, fmt.Errorf("%w: %d", ErrBadTimestampFlag, ts.Flags)
+		}
+	}
+	if len(request.Stamps) != len(response.Stamps) {
+		return nil, fmt.Errorf("%w: %d entries requested, %d returned", ErrPrespecMismatch, len(request.Stamps), len(response.Stamps))
+	}
+	answered := response.recordedEntries()
+	results := make([]PrespecResult, len(request.Stamps))
+	for i, s := range request.Stamps {
+		if response.Stamps[i].Addr != s.Addr {
+			return nil, fmt.Errorf("%w: entry %d is %v, requested %v", ErrPrespecMismatch, i, response.Stamps[i].Addr, s.Addr)
+		}
+		results[i].Addr = s.Addr
+		if i < answered {
+			results[i].Time = response.Stamps[i].Time
+			results[i].Answered = true
+		}
+	}
+	return results, nil
+}
+
+// maxOverflow is the largest value of the 4 bit overflow count.
+const maxOverflow = 15
+
+// Stamp fills in the entry at the pointer as a hop with address addr would
+// at time t, following RFC 791. If the option is full, the overflow count is
+// incremented instead, and ErrOverflowFull is returned if it can not be. For
+// TSPrespec, only a hop whose address matches the entry at the pointer
+// records a timestamp; other hops leave the option unchanged.
+func (ts *TS) Stamp(addr net.IP, t time.Time) error {
+	a, err := AddressFromNetIP(addr)
+	if err != nil {
+		return err
+	}
+	switch ts.Flags {
+	case TSOnly, TSAndAddr, TSPrespec:
+	default:
+		return fmt.Errorf("%w: %d", ErrBadTimestampFlag, ts.Flags)
+	}
+	n := ts.entryLen()
+	if ts.Pointer < 5 || (int(ts.Pointer)-5)%n != 0 {
+		return fmt.Errorf("%w: %d", ErrBadPointer, ts.Pointer)
+	}
+	i := (int(ts.Pointer) - 5) / n
+	if i >= len(ts.Stamps) {
+		if ts.Over >= maxOverflow {
+			return ErrOverflowFull
+		}
+		ts.Over++
+		ts.Recompute()
+		return nil
+	}
+	if ts.Flags == TSPrespec && ts.Stamps[i].Addr != a {
+		return nil
+	}
+	ts.Stamps = slices.Clone(ts.Stamps)
+	if ts.Flags == TSAndAddr {
+		ts.Stamps[i].Addr = a
+	}
+	ts.Stamps[i].Time = timestampOf(t)
+	ts.Pointer += byte(n)
+	ts.Recompute()
+	return nil
+}
@@ -0,0 +1,352 @@
+package ipv4opt_test
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func tsOnly(t *testing.T, stamp uint32) ipv4opt.Options {
+	data := []byte{
+		68, 8, 9, ipv4opt.TSOnly,
+		byte(stamp >> 24), byte(stamp >> 16), byte(stamp >> 8), byte(stamp),
+	}
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	return ops
+}
+
+func TestCheckTimestampMonotonic(t *testing.T) {
+	captures := []ipv4opt.Options{
+		tsOnly(t, 1000),
+		tsOnly(t, 2000),
+		tsOnly(t, 1500),
+	}
+	err := ipv4opt.CheckTimestampMonotonic(captures)
+	if !errors.Is(err, ipv4opt.ErrTimestampOrder) {
+		t.Fatalf("Wrong error, Expected(%v), Got(%v)", ipv4opt.ErrTimestampOrder, err)
+	}
+	captures = []ipv4opt.Options{
+		tsOnly(t, 86399990),
+		tsOnly(t, 5),
+		tsOnly(t, 1500),
+	}
+	if err := ipv4opt.CheckTimestampMonotonic(captures); err != nil {
+		t.Fatalf("Unexpected error for wrapped timestamps: %v", err)
+	}
+}
+
+func TestTSRecompute(t *testing.T) {
+	ops, err := ipv4opt.Parse(tsTest2)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	ts := ops[0].(ipv4opt.TS)
+	ts.Recompute()
+	if !reflect.DeepEqual(ts.Data(), tsTest2) {
+		t.Fatalf("Wrong data in option, Expected(%v), Got(%v)", tsTest2, ts.Data())
+	}
+	ts.Stamps = ts.Stamps[:3]
+	ts.Recompute()
+	if ts.Length() != len(tsTest2)-8 {
+		t.Fatalf("Incorrect option len, Expected(%v), Got(%v)", len(tsTest2)-8, ts.Length())
+	}
+}
+
+func TestNewTimestampAddr(t *testing.T) {
+	stamps := []ipv4opt.Stamp{
+		ipv4opt.Stamp{Addr: 2309292313, Time: 71500652},
+		ipv4opt.Stamp{Addr: 1114449458, Time: 71500645},
+	}
+	ts, err := ipv4opt.NewTimestampAddr(stamps, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts.Length() != 28 {
+		t.Fatalf("Incorrect option len, Expected(%v), Got(%v)", 28, ts.Length())
+	}
+	if ts.Pointer != 21 {
+		t.Fatalf("Wrong pointer, Expected(%v), Got(%v)", 21, ts.Pointer)
+	}
+	ops, err := ipv4opt.Parse(ts.Data())
+	if err != nil {
+		t.Fatalf("Failed to parse built option: %v", err)
+	}
+	parsed := ops[0].(ipv4opt.TS)
+	expected := append(stamps, ipv4opt.Stamp{})
+	if parsed.Flags != ipv4opt.TSAndAddr || !compareStamps(parsed.Stamps, expected, t) {
+		t.Fatalf("Wrong stamps, Expected(%v), Got(%v)", expected, parsed.Stamps)
+	}
+	stamps[0].Addr = 0
+	if _, err := ipv4opt.NewTimestampAddr(stamps, 1); err != ipv4opt.ErrZeroAddress {
+		t.Fatalf("Wrong error, Expected(%v), Got(%v)", ipv4opt.ErrZeroAddress, err)
+	}
+}
+
+func TestTSRoundTrip(t *testing.T) {
+	data := []byte{
+		68, 20, 21, 3, 66, 109, 38, 50, 2, 208, 113, 237,
+		137, 165, 1, 25, 2, 208, 113, 245,
+	}
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	ts := ops[0].(ipv4opt.TS)
+	out, back, ok := ts.RoundTrip()
+	if !ok || out != 47215085 || back != 47215093 {
+		t.Fatalf("Wrong round trip, Expected(%v, %v, %v), Got(%v, %v, %v)", 47215085, 47215093, true, out, back, ok)
+	}
+	ts.Pointer = 13
+	if _, _, ok := ts.RoundTrip(); ok {
+		t.Fatalf("Expected round trip to be incomplete")
+	}
+}
+
+func TestNewTimestampOption(t *testing.T) {
+	tests := []struct {
+		flag     ipv4opt.Flag
+		slots    int
+		prespec  []net.IP
+		expected []byte
+		err      error
+	}{
+		{ipv4opt.TSOnly, 2, nil, []byte{68, 12, 5, 0, 0, 0, 0, 0, 0, 0, 0, 0}, nil},
+		{ipv4opt.TSAndAddr, 1, nil, []byte{68, 12, 5, 1, 0, 0, 0, 0, 0, 0, 0, 0}, nil},
+		{ipv4opt.TSPrespec, 2, []net.IP{net.IPv4(10, 0, 0, 1)}, []byte{
+			68, 20, 5, 3, 10, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		}, nil},
+		{ipv4opt.TSOnly, 9, nil, nil, nil},
+		{ipv4opt.TSOnly, 0, nil, nil, ipv4opt.ErrInvalidOptionLength},
+		{ipv4opt.TSAndAddr, 5, nil, nil, ipv4opt.ErrOptionDataTooLarge},
+		{ipv4opt.TSPrespec, 1, nil, nil, ipv4opt.ErrInvalidOptionLength},
+		{ipv4opt.TSPrespec, 1, []net.IP{net.IPv4zero}, nil, ipv4opt.ErrZeroAddress},
+		{ipv4opt.TSPrespec, 1, []net.IP{net.IPv6loopback}, nil, ipv4opt.ErrBadAddress},
+		{ipv4opt.TSAndAddr, 1, []net.IP{net.IPv4(10, 0, 0, 1)}, nil, ipv4opt.ErrBadTimestampFlag},
+		{2, 1, nil, nil, ipv4opt.ErrBadTimestampFlag},
+	}
+	for _, test := range tests {
+		ts, err := ipv4opt.NewTimestampOption(test.flag, test.slots, test.prespec...)
+		if !errors.Is(err, test.err) {
+			t.Fatalf("Expected(%v), Got(%v)", test.err, err)
+		}
+		if err != nil || test.expected == nil {
+			continue
+		}
+		b, err := ts.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b, test.expected) {
+			t.Fatalf("Expected(%v), Got(%v)", test.expected, b)
+		}
+		if _, err := ipv4opt.Parse(b); err != nil {
+			t.Fatalf("Failed to parse built option: %v", err)
+		}
+	}
+}
+
+func TestTimestampRecorded(t *testing.T) {
+	tests := []struct {
+		data       []byte
+		recorded   int
+		remaining  int
+		overflowed int
+	}{
+		{tsTest, 9, 0, 4},
+		{tsTest2, 4, 0, 6},
+		{tsPreSpec[:12], 1, 0, 4},
+		{[]byte{68, 12, 5, 1, 0, 0, 0, 0, 0, 0, 0, 0}, 0, 1, 0},
+		{[]byte{68, 8, 3, 0, 0, 0, 0, 0}, 0, 1, 0},
+	}
+	for _, test := range tests {
+		ops, err := ipv4opt.Parse(test.data)
+		if err != nil {
+			t.Fatalf("Failed to parse test data: %v", err)
+		}
+		ts := ops.Timestamps()[0]
+		if len(ts.Recorded()) != test.recorded {
+			t.Fatalf("Wrong recorded entries, Expected(%v), Got(%v)", test.recorded, len(ts.Recorded()))
+		}
+		if ts.Remaining() != test.remaining {
+			t.Fatalf("Wrong remaining entries, Expected(%v), Got(%v)", test.remaining, ts.Remaining())
+		}
+		if ts.OverflowedHops() != test.overflowed {
+			t.Fatalf("Wrong overflowed hops, Expected(%v), Got(%v)", test.overflowed, ts.OverflowedHops())
+		}
+	}
+}
+
+func TestTimestampTime(t *testing.T) {
+	date := time.Date(2020, time.March, 1, 23, 0, 0, 0, time.FixedZone("", -5*60*60))
+	tests := []struct {
+		stamp       ipv4opt.Timestamp
+		nonStandard bool
+		duration    time.Duration
+		time        time.Time
+	}{
+		{0, false, 0, time.Date(2020, time.March, 2, 0, 0, 0, 0, time.UTC)},
+		{47215085, false, 47215085 * time.Millisecond, time.Date(2020, time.March, 2, 13, 6, 55, 85e6, time.UTC)},
+		{0x80000010, true, 16 * time.Millisecond, time.Date(2020, time.March, 2, 0, 0, 0, 16e6, time.UTC)},
+	}
+	for _, test := range tests {
+		if test.stamp.NonStandard() != test.nonStandard {
+			t.Fatalf("Wrong non-standard flag, Expected(%v), Got(%v)", test.nonStandard, test.stamp.NonStandard())
+		}
+		if test.stamp.Duration() != test.duration {
+			t.Fatalf("Wrong duration, Expected(%v), Got(%v)", test.duration, test.stamp.Duration())
+		}
+		if got := test.stamp.Time(date); !got.Equal(test.time) {
+			t.Fatalf("Wrong time, Expected(%v), Got(%v)", test.time, got)
+		}
+	}
+}
+
+func TestTSStamp(t *testing.T) {
+	hop1, hop2 := net.IPv4(10, 0, 0, 1), net.IPv4(10, 0, 0, 2)
+	at := time.Date(2020, time.March, 1, 0, 0, 1, 500e6, time.UTC)
+	tests := []struct {
+		flag     ipv4opt.Flag
+		prespec  []net.IP
+		hops     []net.IP
+		expected []byte
+		err      error
+	}{
+		{ipv4opt.TSOnly, nil, []net.IP{hop1}, []byte{68, 8, 9, 0, 0, 0, 0x05, 0xdc}, nil},
+		{ipv4opt.TSOnly, nil, []net.IP{hop1, hop2, hop1}, []byte{68, 8, 9, 0x20, 0, 0, 0x05, 0xdc}, nil},
+		{ipv4opt.TSAndAddr, nil, []net.IP{hop2}, []byte{68, 12, 13, 1, 10, 0, 0, 2, 0, 0, 0x05, 0xdc}, nil},
+		{ipv4opt.TSPrespec, []net.IP{hop2}, []net.IP{hop1}, []byte{68, 12, 5, 3, 10, 0, 0, 2, 0, 0, 0, 0}, nil},
+		{ipv4opt.TSPrespec, []net.IP{hop2}, []net.IP{hop1, hop2}, []byte{68, 12, 13, 3, 10, 0, 0, 2, 0, 0, 0x05, 0xdc}, nil},
+		{ipv4opt.TSOnly, nil, []net.IP{net.IPv6loopback}, nil, ipv4opt.ErrBadAddress},
+	}
+	for i, test := range tests {
+		ts, err := ipv4opt.NewTimestampOption(test.flag, 1, test.prespec...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, hop := range test.hops {
+			err = ts.Stamp(hop, at)
+			if err != nil {
+				break
+			}
+		}
+		if !errors.Is(err, test.err) {
+			t.Fatalf("Test %d, Expected(%v), Got(%v)", i, test.err, err)
+		}
+		if err != nil {
+			continue
+		}
+		if !bytes.Equal(ts.Data(), test.expected) {
+			t.Fatalf("Test %d, Expected(%v), Got(%v)", i, test.expected, ts.Data())
+		}
+	}
+}
+
+func TestTSStampOverflowFull(t *testing.T) {
+	ts, err := ipv4opt.NewTimestampOption(ipv4opt.TSOnly, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 16; i++ {
+		if err := ts.Stamp(net.IPv4(10, 0, 0, 1), time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ts.OverflowedHops() != 15 {
+		t.Fatalf("Expected(%v), Got(%v)", 15, ts.OverflowedHops())
+	}
+	if err := ts.Stamp(net.IPv4(10, 0, 0, 1), time.Now()); !errors.Is(err, ipv4opt.ErrOverflowFull) {
+		t.Fatalf("Expected(%v), Got(%v)", ipv4opt.ErrOverflowFull, err)
+	}
+}
+
+func TestMalformedTimestamp(t *testing.T) {
+	tests := []struct {
+		data []byte
+		err  error
+	}{
+		{[]byte{68, 10, 5, 1, 0, 0, 0, 0, 0, 0}, ipv4opt.ErrBadTimestampLength},
+		{[]byte{68, 10, 5, 3, 0, 0, 0, 0, 0, 0}, ipv4opt.ErrBadTimestampLength},
+		{[]byte{68, 7, 5, 0, 0, 0, 0}, ipv4opt.ErrBadTimestampLength},
+		{[]byte{68, 8, 5, 0, 0, 0, 0, 1}, nil},
+		{[]byte{68, 7, 5, 2, 0, 0, 0}, nil},
+	}
+	for i, test := range tests {
+		ops, err := ipv4opt.Parse(test.data)
+		if !errors.Is(err, test.err) {
+			t.Fatalf("Test %d, Wrong error, Expected(%v), Got(%v)", i, test.err, err)
+		}
+		if test.err != nil && !errors.Is(err, ipv4opt.ErrInvalidOptionLength) {
+			t.Fatalf("Test %d, Expected(%v), Got(%v)", i, ipv4opt.ErrInvalidOptionLength, err)
+		}
+		if err != nil {
+			continue
+		}
+		ts := ops[0].(ipv4opt.TS)
+		errs := ops.Validate()
+		if ts.Flags == 2 {
+			if len(ts.Stamps) != 0 || len(errs) == 0 || !errors.Is(errs[0], ipv4opt.ErrBadTimestampFlag) {
+				t.Fatalf("Test %d, Expected(%v), Got(%v, %v)", i, ipv4opt.ErrBadTimestampFlag, ts.Stamps, errs)
+			}
+		} else if len(errs) != 0 {
+			t.Fatalf("Test %d, Expected no errors, Got(%v)", i, errs)
+		}
+	}
+}
+
+func TestMatchPrespec(t *testing.T) {
+	hosts := []net.IP{net.IPv4(192, 0, 2, 1), net.IPv4(192, 0, 2, 2), net.IPv4(192, 0, 2, 3)}
+	request, err := ipv4opt.NewTimestampOption(ipv4opt.TSPrespec, 3, hosts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := request
+	now := time.Date(2020, 1, 1, 0, 0, 1, 0, time.UTC)
+	for _, h := range []net.IP{hosts[0], net.IPv4(198, 51, 100, 1), hosts[1]} {
+		if err := response.Stamp(h, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	results, err := ipv4opt.MatchPrespec(request, response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []bool{true, true, false}
+	for i, r := range results {
+		if r.Addr.String() != hosts[i].String() || r.Answered != expected[i] {
+			t.Fatalf("Entry %d, Wrong result, Expected(%v, %v), Got(%v)", i, hosts[i], expected[i], r)
+		}
+		if r.Answered && r.Time != 1000 {
+			t.Fatalf("Entry %d, Wrong time, Expected(%v), Got(%v)", i, 1000, r.Time)
+		}
+	}
+
+	other, err := ipv4opt.NewTimestampOption(ipv4opt.TSPrespec, 3, hosts[0], hosts[2], hosts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ipv4opt.MatchPrespec(request, other); !errors.Is(err, ipv4opt.ErrPrespecMismatch) {
+		t.Fatalf("Wrong error, Expected(%v), Got(%v)", ipv4opt.ErrPrespecMismatch, err)
+	}
+	short, err := ipv4opt.NewTimestampOption(ipv4opt.TSPrespec, 2, hosts[:2]...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ipv4opt.MatchPrespec(request, short); !errors.Is(err, ipv4opt.ErrPrespecMismatch) {
+		t.Fatalf("Wrong error, Expected(%v), Got(%v)", ipv4opt.ErrPrespecMismatch, err)
+	}
+	plain, err := ipv4opt.NewTimestampOption(ipv4opt.TSOnly, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ipv4opt.MatchPrespec(plain, response); !errors.Is(err, ipv4opt.ErrBadTimestampFlag) {
+		t.Fatalf("Wrong error, Expected(%v), Got(%v)", ipv4opt.ErrBadTimestampFlag, err)
+	}
+}
@@ -0,0 +1,216 @@
+package ipv4opt
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnexpectedNoOp is returned in strict parsing when a NoOperation
+	// byte falls inside the data of an option with a fixed length.
+	ErrUnexpectedNoOp = fmt.Errorf("NoOperation found inside option data")
+	// ErrDuplicateOption is reported when an option appears more than once.
+	ErrDuplicateOption = fmt.Errorf("Option appears more than once")
+	// ErrPathTruncated is reported when a route or timestamp option ran out
+	// of space before the end of the path.
+	ErrPathTruncated = fmt.Errorf("Recorded path is truncated")
+	// ErrBadPointer is reported when the pointer of a route or timestamp
+	// option does not point at an entry.
+	ErrBadPointer = fmt.Errorf("Invalid option pointer")
+)
+
+// isPadding reports whether opt is a single byte padding option.
+func isPadding(opt IPOption) bool {
+	return isPaddingType(opt.Type())
+}
+
+// isPaddingType reports whether t is a single byte padding option type.
+func isPaddingType(t OptionType) bool {
+	return t == NoOperation || t == EndOfOptionList
+}
+
+// DuplicatePositions returns the indices of every option type that appears
+// more than once in the list. RFC 791 recommends that each option appears at
+// most once per datagram. Padding options are not reported.
+func (o Options) DuplicatePositions() map[OptionType][]int {
+	positions := make(map[OptionType][]int)
+	for i, opt := range o {
+		if isPadding(opt) {
+			continue
+		}
+		positions[opt.Type()] = append(positions[opt.Type()], i)
+	}
+	for t, idx := range positions {
+		if len(idx) < 2 {
+			delete(positions, t)
+		}
+	}
+	return positions
+}
+
+// ParseStrict parses opts like Parse, but also requires the length field of
+// every option to match the number of bytes its parser consumed, so that the
+// options tile the buffer exactly.
+func ParseStrict(opts []byte) (Options, error) {
+	return strictParser.Parse(opts)
+}
+
+// checkDeclaredLength checks that the length field of the option at the
+// start of raw agrees with the length of opt parsed from it.
+func checkDeclaredLength(raw []byte, opt IPOption) error {
+	if isPadding(opt) {
+		return nil
+	}
+	declared := int(raw[1])
+	if declared == opt.Length() {
+		return nil
+	}
+	if declared >= 2 && declared < opt.Length() && raw[declared] == NoOperation {
+		return ErrUnexpectedNoOp
+	}
+	return ErrInvalidOptionLength
+}
+
+// ValidationReport holds the problems found in a list of options. Errors make
+// the options invalid, warnings describe legal but unusual contents.
+type ValidationReport struct {
+	errors   []error
+	warnings []error
+}
+
+// Errors returns the problems that make the options invalid.
+func (r *ValidationReport) Errors() []error {
+	return r.errors
+}
+
+// Warnings returns the problems that do not make the options invalid.
+func (r *ValidationReport) Warnings() []error {
+	return r.warnings
+}
+
+// OK reports whether no errors were found.
+func (r *ValidationReport) OK() bool {
+	return len(r.errors) == 0
+}
+
+// Report runs all structural and semantic checks on the options and returns
+// the results.
+func (o Options) Report() *ValidationReport {
+	r := &ValidationReport{}
+	if padLen(o.length()) > MaxOptionsLen {
+		r.errors = append(r.errors, ErrOptionDataTooLarge)
+	}
+	for i, opt := range o {
+		if len(dataOf(opt)) != opt.Length() {
+			r.errors = append(r.errors, fmt.Errorf("option %d: %w", i, ErrInvalidOptionLength))
+		}
+		if l, ok := asAddressList(opt); ok {
+			if (l.Length()-3)%4 != 0 {
+				r.errors = append(r.errors, fmt.Errorf("option %d: %w", i, ErrIncorrectRRLength))
+			}
+			if err := l.checkPointer(); err != nil {
+				r.errors = append(r.errors, fmt.Errorf("option %d: %w", i, err))
+			}
+			if l.Exhausted() {
+				r.warnings = append(r.warnings, fmt.Errorf("option %d: %w", i, ErrPathTruncated))
+			}
+		}
+		if ts, ok := opt.(TS); ok && ts.Over > 0 {
+			r.warnings = append(r.warnings, fmt.Errorf("option %d: %w", i, ErrPathTruncated))
+		}
+	}
+	for t, idx := range o.DuplicatePositions() {
+		r.warnings = append(r.warnings, fmt.Errorf("option type %d at %v: %w", t, idx, ErrDuplicateOption))
+	}
+	return r
+}
+
+type validator interface {
+	Validate() error
+}
+
+// Validate checks every option in the list against the rules of the RFC
+// defining it and returns the problems found, each wrapped in an
+// OptionError. Parsing accepts options that break these rules so that they
+// can still be inspected.
+func (o Options) Validate() []error {
+	var errs []error
+	var offset int
+	for _, opt := range o {
+		var err error
+		if len(dataOf(opt)) != opt.Length() {
+			err = ErrInvalidOptionLength
+		} else if v, ok := opt.(validator); ok {
+			err = v.Validate()
+		}
+		if err != nil {
+			errs = append(errs, &OptionError{Type: opt.Type(), Offset: offset, Err: err})
+		}
+		offset += opt.Length()
+	}
+	if padLen(offset) > MaxOptionsLen {
+		errs = append(errs, ErrOptionDataTooLarge)
+	}
+	return errs
+}
+
+// Validate checks that the option has the fixed length of 11 bytes from
+// RFC 791.
+func (s Sec) Validate() error {
+	if s.Length() != securityOpLen {
+		return ErrInvalidOptionLength
+	}
+	return nil
+}
+
+// Validate checks that the option has the fixed length of 4 bytes from
+// RFC 791.
+func (s StreamID) Validate() error {
+	if s.Length() != streamIDOptLen {
+		return ErrInvalidOptionLength
+	}
+	return nil
+}
+
+// Validate checks that the route data is a whole number of addresses and
+// that the pointer is at least 4, at most one past the end of the option and
+// points at the start of an address.
+func (l AddressList) Validate() error {
+	var errs []error
+	if (l.Length()-3)%4 != 0 {
+		errs = append(errs, ErrIncorrectRRLength)
+	}
+	if err := l.checkPointer(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks that the flag is defined, that the data is a whole number
+// of entries, that the pointer is at least 5 and points at the start of an
+// entry, and, for TSPrespec, that the entries not yet stamped carry an
+// address.
+func (ts TS) Validate() error {
+	var errs []error
+	switch ts.Flags {
+	case TSOnly, TSAndAddr, TSPrespec:
+		if (ts.Length()-4)%ts.entryLen() != 0 {
+			errs = append(errs, ErrBadTimestampLength)
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%w: %d", ErrBadTimestampFlag, ts.Flags))
+	}
+	n := ts.entryLen()
+	if ts.Pointer < 5 || (int(ts.Pointer)-5)%n != 0 {
+		errs = append(errs, fmt.Errorf("%w: %d", ErrBadPointer, ts.Pointer))
+	}
+	if ts.Flags == TSPrespec {
+		for _, s := range ts.Stamps[ts.recordedEntries():] {
+			if s.Addr == 0 {
+				errs = append(errs, ErrZeroAddress)
+				break
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
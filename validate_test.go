@@ -0,0 +1,147 @@
+package ipv4opt_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+var secTest = []byte{
+	130, 11, 0xD7, 0x88, 0, 0, 'A', 'B', 0, 0, 0,
+}
+
+func TestDuplicatePositions(t *testing.T) {
+	data := append(append(append([]byte{}, secTest...), ipv4opt.NoOperation), secTest...)
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	expected := map[ipv4opt.OptionType][]int{
+		ipv4opt.Security: []int{0, 2},
+	}
+	dups := ops.DuplicatePositions()
+	if !reflect.DeepEqual(dups, expected) {
+		t.Fatalf("Wrong duplicates, Expected(%v), Got(%v)", expected, dups)
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	if _, err := ipv4opt.ParseStrict(secTest); err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	data := []byte{130, 6, 0xD7, 0x88, 0, 0, 1, 1, 1, 1, 1}
+	if _, err := ipv4opt.Parse(data); err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	if _, err := ipv4opt.ParseStrict(data); !errors.Is(err, ipv4opt.ErrUnexpectedNoOp) {
+		t.Fatalf("Wrong error, Expected(%v), Got(%v)", ipv4opt.ErrUnexpectedNoOp, err)
+	}
+}
+
+func TestReport(t *testing.T) {
+	sec, err := ipv4opt.Parse(secTest)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	ts, err := ipv4opt.Parse(tsTest)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	ops := ipv4opt.Options{sec[0], sec[0], ts[0]}
+	report := ops.Report()
+	if report.OK() {
+		t.Fatalf("Expected report to contain errors")
+	}
+	if len(report.Errors()) != 1 || report.Errors()[0] != ipv4opt.ErrOptionDataTooLarge {
+		t.Fatalf("Wrong errors, Expected(%v), Got(%v)", ipv4opt.ErrOptionDataTooLarge, report.Errors())
+	}
+	var dup, truncated bool
+	for _, w := range report.Warnings() {
+		dup = dup || errors.Is(w, ipv4opt.ErrDuplicateOption)
+		truncated = truncated || errors.Is(w, ipv4opt.ErrPathTruncated)
+	}
+	if !dup || !truncated {
+		t.Fatalf("Missing warnings, Got(%v)", report.Warnings())
+	}
+	if !sec.Report().OK() {
+		t.Fatalf("Unexpected errors: %v", sec.Report().Errors())
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		data   []byte
+		err    error
+		offset int
+	}{
+		{rrEmptyTest[:11], nil, 0},
+		{tsPreSpec[:12], nil, 0},
+		{secTest, nil, 0},
+		{[]byte{1, 7, 7, 3, 0, 0, 0, 0}, ipv4opt.ErrBadPointer, 1},
+		{[]byte{7, 7, 6, 0, 0, 0, 0}, ipv4opt.ErrBadPointer, 0},
+		{[]byte{7, 7, 12, 0, 0, 0, 0}, ipv4opt.ErrBadPointer, 0},
+		{[]byte{7, 7, 8, 0, 0, 0, 0}, nil, 0},
+		{[]byte{68, 8, 5, 2, 0, 0, 0, 0}, ipv4opt.ErrBadTimestampFlag, 0},
+		{[]byte{68, 12, 5, 3, 0, 0, 0, 0, 0, 0, 0, 0}, ipv4opt.ErrZeroAddress, 0},
+		{[]byte{68, 12, 7, 3, 10, 0, 0, 1, 0, 0, 0, 0}, ipv4opt.ErrBadPointer, 0},
+		{append(sidTest, 136, 6, 0, 0, 0x12, 0x34), ipv4opt.ErrInvalidOptionLength, 4},
+	}
+	for i, test := range tests {
+		ops, err := ipv4opt.Parse(test.data)
+		if err != nil {
+			t.Fatalf("Failed to parse test data: %v", err)
+		}
+		errs := ops.Validate()
+		if test.err == nil {
+			if len(errs) != 0 {
+				t.Fatalf("Test %d, Expected no errors, Got(%v)", i, errs)
+			}
+			continue
+		}
+		if len(errs) != 1 || !errors.Is(errs[0], test.err) {
+			t.Fatalf("Test %d, Expected(%v), Got(%v)", i, test.err, errs)
+		}
+		var oe *ipv4opt.OptionError
+		if !errors.As(errs[0], &oe) || oe.Offset != test.offset {
+			t.Fatalf("Test %d, Wrong offset, Expected(%v), Got(%v)", i, test.offset, errs[0])
+		}
+	}
+}
+
+func TestBadRoutePointer(t *testing.T) {
+	tests := []struct {
+		data      []byte
+		exhausted bool
+		err       error
+	}{
+		{[]byte{7, 7, 4, 0, 0, 0, 0}, false, nil},
+		{[]byte{7, 7, 8, 0, 0, 0, 0}, true, nil},
+		{[]byte{7, 7, 3, 0, 0, 0, 0}, false, ipv4opt.ErrBadPointer},
+		{[]byte{7, 7, 200, 0, 0, 0, 0}, false, ipv4opt.ErrBadPointer},
+	}
+	for i, test := range tests {
+		ops, err := ipv4opt.Parse(test.data)
+		if err != nil {
+			t.Fatalf("Failed to parse test data: %v", err)
+		}
+		rr := ops[0].(ipv4opt.RR)
+		if rr.Exhausted() != test.exhausted {
+			t.Fatalf("Test %d, Wrong exhausted, Expected(%v), Got(%v)", i, test.exhausted, rr.Exhausted())
+		}
+		report := ops.Report()
+		if test.err == nil {
+			if !report.OK() {
+				t.Fatalf("Test %d, Expected no errors, Got(%v)", i, report.Errors())
+			}
+			continue
+		}
+		if len(report.Errors()) != 1 || !errors.Is(report.Errors()[0], test.err) {
+			t.Fatalf("Test %d, Expected(%v), Got(%v)", i, test.err, report.Errors())
+		}
+		if _, ok := rr.NextHop(); ok {
+			t.Fatalf("Test %d, Expected no next hop", i)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package ipv4opt
+
+// The view types hold the decoded fields of an option as plain exported
+// values, with the type as its IANA name and addresses in dotted-quad form,
+// for encoders that work by reflection and can not see the unexported fields
+// shared by the option types.
+
+// RRView is the view of a record route, source route, SDB or UMP option.
+type RRView struct {
+	Type    string
+	Length  int
+	Pointer int
+	Routes  []string
+}
+
+// StampView is the view of a timestamp entry. Addr is empty for TSOnly
+// options.
+type StampView struct {
+	Time uint32
+	Addr string
+}
+
+// TSView is the view of a timestamp option.
+type TSView struct {
+	Type     string
+	Length   int
+	Pointer  int
+	Flags    int
+	Overflow int
+	Stamps   []StampView
+}
+
+// SecView is the view of an RFC 791 security option.
+type SecView struct {
+	Type        string
+	Length      int
+	Level       uint16
+	Compartment uint16
+	Restriction uint16
+	TCC         uint32
+}
+
+// BasicSecView is the view of an RFC 1108 basic security option.
+type BasicSecView struct {
+	Type           string
+	Length         int
+	Classification uint8
+	Authorities    []byte
+}
+
+// ESecView is the view of an RFC 1108 extended security option.
+type ESecView struct {
+	Type   string
+	Length int
+	Format uint8
+	Info   []byte
+}
+
+// UInt16View is the view of an option carrying a single 16-bit value: stream
+// identifier, router alert, MTU probe and reply, and other UInt16Options.
+type UInt16View struct {
+	Type   string
+	Length int
+	Value  uint16
+}
+
+// TracerouteView is the view of a traceroute option.
+type TracerouteView struct {
+	Type         string
+	Length       int
+	ID           uint16
+	OutboundHops uint16
+	ReturnHops   uint16
+	Originator   string
+}
+
+// QuickStartView is the view of a quick-start option.
+type QuickStartView struct {
+	Type     string
+	Length   int
+	Function uint8
+	Rate     uint8
+	TTL      uint8
+	Nonce    uint32
+}
+
+// CIPSOView is the view of a CIPSO option.
+type CIPSOView struct {
+	Type   string
+	Length int
+	DOI    uint32
+	Tags   []CIPSOTag
+}
+
+// RawView is the view of any other option. Payload holds the bytes after the
+// type and length.
+type RawView struct {
+	Type    string
+	Length  int
+	Payload []byte
+}
+
+// View returns the view of every option in the list, in order. Each element
+// is one of the view types of this package.
+func (o Options) View() []interface{} {
+	views := make([]interface{}, len(o))
+	for i, opt := range o {
+		views[i] = ViewOf(opt)
+	}
+	return views
+}
+
+// ViewOf returns the view of opt: an RRView, TSView, SecView, BasicSecView,
+// ESecView, UInt16View, TracerouteView, QuickStartView or CIPSOView for the
+// options decoded into those fields, and a RawView otherwise.
+func ViewOf(opt IPOption) interface{} {
+	name, length := optionName(opt.Type()), opt.Length()
+	if l, ok := asAddressList(opt); ok {
+		v := RRView{Type: name, Length: length, Pointer: int(l.Pointer), Routes: make([]string, len(l.Routes))}
+		for i, r := range l.Routes {
+			v.Routes[i] = r.String()
+		}
+		return v
+	}
+	switch o := opt.(type) {
+	case TS:
+		v := TSView{Type: name, Length: length, Pointer: int(o.Pointer), Flags: int(o.Flags), Overflow: int(o.Over), Stamps: make([]StampView, len(o.Stamps))}
+		for i, s := range o.Stamps {
+			v.Stamps[i].Time = uint32(s.Time)
+			if o.Flags != TSOnly {
+				v.Stamps[i].Addr = s.Addr.String()
+			}
+		}
+		return v
+	case Sec:
+		return SecView{Type: name, Length: length, Level: uint16(o.Level), Compartment: uint16(o.Compartment), Restriction: uint16(o.Restriction), TCC: uint32(o.TCC)}
+	case BasicSec:
+		return BasicSecView{Type: name, Length: length, Classification: uint8(o.Classification), Authorities: append([]byte(nil), o.Authorities...)}
+	case ESec:
+		return ESecView{Type: name, Length: length, Format: o.Format, Info: append([]byte(nil), o.Info...)}
+	case UInt16Option:
+		return UInt16View{Type: name, Length: length, Value: o.Value}
+	case StreamID:
+		return UInt16View{Type: name, Length: length, Value: o.ID}
+	case RouterAlert:
+		return UInt16View{Type: name, Length: length, Value: o.Value}
+	case MTUProbe:
+		return UInt16View{Type: name, Length: length, Value: o.MTU}
+	case MTUReply:
+		return UInt16View{Type: name, Length: length, Value: o.MTU}
+	case Traceroute:
+		return TracerouteView{Type: name, Length: length, ID: o.ID, OutboundHops: o.OutboundHops, ReturnHops: o.ReturnHops, Originator: o.Originator.String()}
+	case QuickStart:
+		return QuickStartView{Type: name, Length: length, Function: o.Function, Rate: o.Rate, TTL: o.TTL, Nonce: o.Nonce}
+	case CIPSO:
+		return CIPSOView{Type: name, Length: length, DOI: o.DOI, Tags: o.Tags}
+	}
+	return RawView{Type: name, Length: length, Payload: opt.Payload()}
+}
@@ -0,0 +1,46 @@
+package ipv4opt_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rhansen2/ipv4optparser"
+)
+
+func TestView(t *testing.T) {
+	data := []byte{
+		131, 11, 8, 192, 0, 2, 1, 0, 0, 0, 0,
+		68, 12, 13, 3, 198, 51, 100, 9, 0, 0, 0, 5,
+		148, 4, 0, 0,
+		1, 31, 3, 0xff,
+	}
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	expected := []interface{}{
+		ipv4opt.RRView{Type: "LSR", Length: 11, Pointer: 8, Routes: []string{"192.0.2.1", "0.0.0.0"}},
+		ipv4opt.TSView{Type: "TS", Length: 12, Pointer: 13, Flags: 3, Stamps: []ipv4opt.StampView{{Time: 5, Addr: "198.51.100.9"}}},
+		ipv4opt.UInt16View{Type: "RTRALT", Length: 4},
+		ipv4opt.RawView{Type: "NOP", Length: 1},
+		ipv4opt.RawView{Type: "31", Length: 3, Payload: []byte{0xff}},
+	}
+	if views := ops.View(); !reflect.DeepEqual(views, expected) {
+		t.Fatalf("Wrong views, Expected(%+v), Got(%+v)", expected, views)
+	}
+}
+
+func TestSecurityViews(t *testing.T) {
+	data := []byte{130, 4, 0x5a, 0x90, 133, 6, 1, 0xaa, 0xbb, 0xcc}
+	ops, err := ipv4opt.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+	expected := []interface{}{
+		ipv4opt.BasicSecView{Type: "SEC", Length: 4, Classification: 0x5a, Authorities: []byte{0x90}},
+		ipv4opt.ESecView{Type: "E-SEC", Length: 6, Format: 1, Info: []byte{0xaa, 0xbb, 0xcc}},
+	}
+	if views := ops.View(); !reflect.DeepEqual(views, expected) {
+		t.Fatalf("Wrong views, Expected(%+v), Got(%+v)", expected, views)
+	}
+}